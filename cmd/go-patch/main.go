@@ -0,0 +1,133 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/SUSE/go-patch/patch"
+)
+
+const usage = `Usage: go-patch [-o ops.yml]... [-output path] [-json] base.yml
+
+Applies operations files in order to a YAML or JSON document
+and prints the result. Use '-' as base document to read from stdin.
+
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+type opsFilesFlag []string
+
+func (f *opsFilesFlag) String() string { return strings.Join(*f, ",") }
+
+func (f *opsFilesFlag) Set(path string) error {
+	*f = append(*f, path)
+	return nil
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	var (
+		opsFiles   opsFilesFlag
+		outputPath string
+		outputJSON bool
+	)
+
+	flags := flag.NewFlagSet("go-patch", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		flags.PrintDefaults()
+	}
+
+	flags.Var(&opsFiles, "o", "Path to an operations file (can be specified multiple times)")
+	flags.StringVar(&outputPath, "output", "", "Path to write result to instead of stdout")
+	flags.BoolVar(&outputJSON, "json", false, "Write result as JSON instead of YAML")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return 2
+	}
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	err = applyOpsFiles(flags.Arg(0), opsFiles, outputPath, outputJSON, stdin, stdout)
+	if err != nil {
+		fmt.Fprintf(stderr, "go-patch: %s\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+func applyOpsFiles(basePath string, opsFiles []string, outputPath string, outputJSON bool, stdin io.Reader, stdout io.Writer) error {
+	var doc interface{}
+
+	bytes, err := readFile(basePath, stdin)
+	if err != nil {
+		return fmt.Errorf("Reading base document '%s': %s", basePath, err)
+	}
+
+	err = yaml.Unmarshal(bytes, &doc)
+	if err != nil {
+		return fmt.Errorf("Deserializing base document '%s': %s", basePath, err)
+	}
+
+	for _, opsPath := range opsFiles {
+		var opDefs []patch.OpDefinition
+
+		bytes, err := readFile(opsPath, stdin)
+		if err != nil {
+			return fmt.Errorf("Reading operations file '%s': %s", opsPath, err)
+		}
+
+		err = yaml.Unmarshal(bytes, &opDefs)
+		if err != nil {
+			return fmt.Errorf("Deserializing operations file '%s': %s", opsPath, err)
+		}
+
+		ops, err := patch.NewOpsFromDefinitions(opDefs)
+		if err != nil {
+			return fmt.Errorf("Building operations from '%s': %s", opsPath, err)
+		}
+
+		doc, err = ops.Apply(doc)
+		if err != nil {
+			return fmt.Errorf("Applying operations from '%s': %s", opsPath, err)
+		}
+	}
+
+	bytes, err = serialize(doc, outputJSON)
+	if err != nil {
+		return fmt.Errorf("Serializing result: %s", err)
+	}
+
+	if len(outputPath) > 0 {
+		err = ioutil.WriteFile(outputPath, bytes, 0644)
+		if err != nil {
+			return fmt.Errorf("Writing result to '%s': %s", outputPath, err)
+		}
+		return nil
+	}
+
+	_, err = stdout.Write(bytes)
+
+	return err
+}
+
+func readFile(path string, stdin io.Reader) ([]byte, error) {
+	if path == "-" {
+		return ioutil.ReadAll(stdin)
+	}
+	return ioutil.ReadFile(path)
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("run", func() {
+	var (
+		tmpDir         string
+		stdout, stderr *bytes.Buffer
+	)
+
+	writeFile := func(name, contents string) string {
+		path := filepath.Join(tmpDir, name)
+		err := ioutil.WriteFile(path, []byte(contents), 0644)
+		Expect(err).ToNot(HaveOccurred())
+		return path
+	}
+
+	BeforeEach(func() {
+		var err error
+
+		tmpDir, err = ioutil.TempDir("", "go-patch")
+		Expect(err).ToNot(HaveOccurred())
+
+		stdout = &bytes.Buffer{}
+		stderr = &bytes.Buffer{}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	It("applies operations files in order and prints result", func() {
+		base := writeFile("base.yml", "name: foo\ninstances: 1\n")
+		ops1 := writeFile("ops1.yml", "- type: replace\n  path: /instances\n  value: 2\n")
+		ops2 := writeFile("ops2.yml", "- type: replace\n  path: /instances\n  value: 3\n")
+
+		code := run([]string{"-o", ops1, "-o", ops2, base}, nil, stdout, stderr)
+		Expect(code).To(Equal(0))
+		Expect(stdout.String()).To(Equal("instances: 3\nname: foo\n"))
+		Expect(stderr.String()).To(BeEmpty())
+	})
+
+	It("prints base document if there are no operations files", func() {
+		base := writeFile("base.yml", "name: foo\n")
+
+		code := run([]string{base}, nil, stdout, stderr)
+		Expect(code).To(Equal(0))
+		Expect(stdout.String()).To(Equal("name: foo\n"))
+	})
+
+	It("reads base document from stdin when '-' is given", func() {
+		ops := writeFile("ops.yml", "- type: remove\n  path: /name\n")
+
+		code := run([]string{"-o", ops, "-"}, strings.NewReader(`{"name": "foo", "a": 1}`), stdout, stderr)
+		Expect(code).To(Equal(0))
+		Expect(stdout.String()).To(Equal("a: 1\n"))
+	})
+
+	It("writes result as JSON", func() {
+		base := writeFile("base.json", `{"items": [{"name": "foo"}]}`)
+		ops := writeFile("ops.yml", "- type: replace\n  path: /items/name=foo/count?\n  value: 1\n")
+
+		code := run([]string{"-json", "-o", ops, base}, nil, stdout, stderr)
+		Expect(code).To(Equal(0))
+		Expect(stdout.String()).To(MatchJSON(`{"items": [{"name": "foo", "count": 1}]}`))
+	})
+
+	It("writes result to a file", func() {
+		base := writeFile("base.yml", "name: foo\n")
+		out := filepath.Join(tmpDir, "out.yml")
+
+		code := run([]string{"-output", out, base}, nil, stdout, stderr)
+		Expect(code).To(Equal(0))
+		Expect(stdout.String()).To(BeEmpty())
+
+		contents, err := ioutil.ReadFile(out)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(contents)).To(Equal("name: foo\n"))
+	})
+
+	It("returns non-zero exit code with descriptive error if operation fails", func() {
+		base := writeFile("base.yml", "name: foo\n")
+		ops := writeFile("ops.yml", "- type: remove\n  path: /not-there\n  error: Custom error message\n")
+
+		code := run([]string{"-o", ops, base}, nil, stdout, stderr)
+		Expect(code).To(Equal(1))
+		Expect(stdout.String()).To(BeEmpty())
+		Expect(stderr.String()).To(Equal(
+			"go-patch: Applying operations from '" + ops + "': Error 'Custom error message': " +
+				"Expected to find a map key 'not-there' for path '/not-there' (found map keys: 'name')\n"))
+	})
+
+	It("returns non-zero exit code if operations file is invalid", func() {
+		base := writeFile("base.yml", "name: foo\n")
+		ops := writeFile("ops.yml", "- type: unknown\n")
+
+		code := run([]string{"-o", ops, base}, nil, stdout, stderr)
+		Expect(code).To(Equal(1))
+		Expect(stderr.String()).To(ContainSubstring("Unknown operation [0] with type 'unknown'"))
+	})
+
+	It("returns non-zero exit code if files cannot be read", func() {
+		code := run([]string{filepath.Join(tmpDir, "not-there.yml")}, nil, stdout, stderr)
+		Expect(code).To(Equal(1))
+		Expect(stderr.String()).To(ContainSubstring("Reading base document"))
+	})
+
+	It("returns usage error if base document is not given", func() {
+		code := run([]string{}, nil, stdout, stderr)
+		Expect(code).To(Equal(2))
+		Expect(stderr.String()).To(ContainSubstring("Usage: go-patch"))
+	})
+})
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/yaml.v2"
+)
+
+func serialize(doc interface{}, asJSON bool) ([]byte, error) {
+	if !asJSON {
+		return yaml.Marshal(doc)
+	}
+
+	bytes, err := json.MarshalIndent(jsonCompatible(doc), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(bytes, '\n'), nil
+}
+
+// jsonCompatible converts maps produced by yaml library
+// (map[interface{}]interface{}) into maps with string keys
+func jsonCompatible(in interface{}) interface{} {
+	switch typedIn := in.(type) {
+	case map[interface{}]interface{}:
+		out := map[string]interface{}{}
+		for k, v := range typedIn {
+			out[fmt.Sprintf("%v", k)] = jsonCompatible(v)
+		}
+		return out
+
+	case []interface{}:
+		out := make([]interface{}, len(typedIn))
+		for i, v := range typedIn {
+			out[i] = jsonCompatible(v)
+		}
+		return out
+
+	default:
+		return in
+	}
+}
@@ -0,0 +1,44 @@
+package patch
+
+import (
+	"fmt"
+)
+
+// AddOp creates map keys and inserts array items following ReplaceOp rules
+// but never overwrites existing values: it fails if map key or array item
+// referred to by the last token already exists.
+type AddOp struct {
+	Path  Pointer
+	Value interface{} // will be deep copied (see docMapFlavor.cloneValue)
+}
+
+func (op AddOp) path() Pointer           { return op.Path }
+func (op AddOp) withPath(ptr Pointer) Op { op.Path = ptr; return op }
+
+func (op AddOp) Apply(doc interface{}) (interface{}, error) {
+	return op.apply(doc, nil)
+}
+
+func (op AddOp) apply(doc interface{}, m matchers) (interface{}, error) {
+	if res, found, err := applyWildcard(op, doc, m); found {
+		return res, err
+	}
+
+	if len(op.Path.Tokens()) == 1 && doc != nil {
+		return nil, fmt.Errorf("Cannot add entire document over existing document")
+	}
+
+	return ReplaceOp{Path: op.Path.lastKeyOptional(), Value: op.Value}.set(doc, m, op.checkMissing)
+}
+
+func (op AddOp) checkMissing(loc walkLocation) (bool, error) {
+	if !loc.Found {
+		return true, nil
+	}
+
+	if loc.IsMap {
+		return false, OpExistingMapKeyErr{loc.Key, op.Path}
+	}
+
+	return false, OpExistingIndexErr{loc.Index, op.Path}
+}
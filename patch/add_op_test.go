@@ -0,0 +1,161 @@
+package patch_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/SUSE/go-patch/patch"
+)
+
+var _ = Describe("AddOp.Apply", func() {
+	It("adds document if there is no document", func() {
+		res, err := AddOp{Path: MustNewPointerFromString(""), Value: "b"}.Apply(nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal("b"))
+
+		_, err = AddOp{Path: MustNewPointerFromString(""), Value: "b"}.Apply("a")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Cannot add entire document over existing document"))
+	})
+
+	It("uses cloned value", func() {
+		val := map[interface{}]interface{}{"a": "b"}
+
+		res, err := AddOp{Path: MustNewPointerFromString("/key"), Value: val}.Apply(map[interface{}]interface{}{})
+		Expect(err).ToNot(HaveOccurred())
+
+		res.(map[interface{}]interface{})["key"].(map[interface{}]interface{})["c"] = "d"
+		Expect(val).To(Equal(map[interface{}]interface{}{"a": "b"}))
+	})
+
+	Describe("map key", func() {
+		It("adds missing map key", func() {
+			doc := map[interface{}]interface{}{"abc": 123}
+
+			res, err := AddOp{Path: MustNewPointerFromString("/xyz"), Value: 456}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{"abc": 123, "xyz": 456}))
+		})
+
+		It("creates missing parents if they are optional", func() {
+			doc := map[interface{}]interface{}{}
+
+			res, err := AddOp{Path: MustNewPointerFromString("/abc?/items/-"), Value: 1}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{
+				"abc": map[interface{}]interface{}{"items": []interface{}{1}},
+			}))
+		})
+
+		It("returns an error if map key already exists", func() {
+			doc := map[interface{}]interface{}{
+				"abc": map[interface{}]interface{}{"xyz": 123},
+			}
+
+			for _, path := range []string{"/abc/xyz", "/abc/xyz?", "/abc?/xyz"} {
+				_, err := AddOp{Path: MustNewPointerFromString(path), Value: 456}.Apply(doc)
+				Expect(err).To(HaveOccurred())
+				Expect(err).To(BeAssignableToTypeOf(OpExistingMapKeyErr{}))
+				Expect(err.Error()).To(Equal(
+					"Expected to not find a map key 'xyz' for path '" + path + "' (use replace operation to overwrite it)"))
+			}
+
+			Expect(doc).To(Equal(map[interface{}]interface{}{
+				"abc": map[interface{}]interface{}{"xyz": 123},
+			}))
+		})
+
+		It("returns an error if existing map key is null", func() {
+			doc := map[interface{}]interface{}{"abc": nil}
+
+			_, err := AddOp{Path: MustNewPointerFromString("/abc"), Value: 456}.Apply(doc)
+			Expect(err).To(HaveOccurred())
+			Expect(err).To(BeAssignableToTypeOf(OpExistingMapKeyErr{}))
+		})
+
+		It("returns an error if parent does not exist", func() {
+			_, err := AddOp{Path: MustNewPointerFromString("/abc/xyz"), Value: 456}.Apply(map[interface{}]interface{}{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected to find a map key 'abc' for path '/abc' (found no other map keys)"))
+		})
+	})
+
+	Describe("array item", func() {
+		It("inserts array items", func() {
+			res, err := AddOp{Path: MustNewPointerFromString("/-"), Value: 10}.Apply([]interface{}{1, 2})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{1, 2, 10}))
+
+			res, err = AddOp{Path: MustNewPointerFromString("/0:before"), Value: 10}.Apply([]interface{}{1, 2})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{10, 1, 2}))
+
+			res, err = AddOp{Path: MustNewPointerFromString("/0:after"), Value: 10}.Apply([]interface{}{1, 2})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{1, 10, 2}))
+		})
+
+		It("appends missing matching item", func() {
+			doc := []interface{}{map[interface{}]interface{}{"name": "a"}}
+
+			res, err := AddOp{Path: MustNewPointerFromString("/name=b?/val"), Value: 10}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{
+				map[interface{}]interface{}{"name": "a"},
+				map[interface{}]interface{}{"name": "b", "val": 10},
+			}))
+		})
+
+		It("returns an error if array item already exists", func() {
+			_, err := AddOp{Path: MustNewPointerFromString("/1"), Value: 10}.Apply([]interface{}{1, 2})
+			Expect(err).To(HaveOccurred())
+			Expect(err).To(BeAssignableToTypeOf(OpExistingIndexErr{}))
+			Expect(err.Error()).To(Equal(
+				"Expected to not find array index '1' for path '/1' (use '-', ':before' or ':after' to insert array items)"))
+
+			doc := []interface{}{map[interface{}]interface{}{"name": "a"}}
+
+			_, err = AddOp{Path: MustNewPointerFromString("/name=a?"), Value: 10}.Apply(doc)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(
+				"Expected to not find array index '0' for path '/name=a?' (use '-', ':before' or ':after' to insert array items)"))
+		})
+	})
+
+	Describe("wildcards", func() {
+		It("adds to every item", func() {
+			doc := []interface{}{
+				map[interface{}]interface{}{"name": "a"},
+				map[interface{}]interface{}{"name": "b"},
+			}
+
+			res, err := AddOp{Path: MustNewPointerFromString("/*/val"), Value: 10}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{
+				map[interface{}]interface{}{"name": "a", "val": 10},
+				map[interface{}]interface{}{"name": "b", "val": 10},
+			}))
+		})
+
+		It("returns an error if any item already has the key", func() {
+			doc := []interface{}{
+				map[interface{}]interface{}{"name": "a"},
+				map[interface{}]interface{}{"name": "b", "val": 1},
+			}
+
+			_, err := AddOp{Path: MustNewPointerFromString("/*/val"), Value: 10}.Apply(doc)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Expected to not find a map key 'val' for path '/1/val'"))
+		})
+	})
+
+	It("works with documents decoded by encoding/json", func() {
+		doc := map[string]interface{}{"abc": map[string]interface{}{}}
+
+		res, err := AddOp{Path: MustNewPointerFromString("/abc/xyz"), Value: map[string]interface{}{"a": 1}}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(map[string]interface{}{
+			"abc": map[string]interface{}{"xyz": map[string]interface{}{"a": 1}},
+		}))
+	})
+})
@@ -0,0 +1,78 @@
+package patch
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// AppendUniqueOp appends value to an array at given path only if array does not have
+// an equal item yet (or an item with the same value of Key, e.g. 'name', if Key is set),
+// so that applying it multiple times results in a single item. Existing items are left untouched.
+// Missing array is created if path is optional.
+type AppendUniqueOp struct {
+	Path  Pointer
+	Value interface{} // will be deep copied (see docMapFlavor.cloneValue)
+	Key   string
+}
+
+func (op AppendUniqueOp) path() Pointer           { return op.Path }
+func (op AppendUniqueOp) withPath(ptr Pointer) Op { op.Path = ptr; return op }
+
+func (op AppendUniqueOp) Apply(doc interface{}) (interface{}, error) {
+	return op.apply(doc, nil)
+}
+
+func (op AppendUniqueOp) apply(doc interface{}, m matchers) (interface{}, error) {
+	if res, found, err := applyWildcard(op, doc, m); found {
+		return res, err
+	}
+
+	replaceOp, found, err := op.replaceOp(doc, m)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return doc, nil
+	}
+
+	return replaceOp.apply(doc, m)
+}
+
+// replaceOp returns operation that appends value (or creates missing array with value);
+// false is returned if array already has such item
+func (op AppendUniqueOp) replaceOp(doc interface{}, m matchers) (ReplaceOp, bool, error) {
+	var keyVal interface{}
+
+	if len(op.Key) > 0 {
+		var found bool
+
+		keyVal, found = matchingValue(op.Value, op.Key)
+		if !found {
+			return ReplaceOp{}, false, fmt.Errorf("Expected value to have key '%s' for path '%s'", op.Key, op.Path)
+		}
+	}
+
+	loc, items, err := findArray(op.Path, doc, m)
+	if err != nil {
+		return ReplaceOp{}, false, err
+	}
+
+	if loc.missing {
+		return ReplaceOp{Path: op.Path, Value: []interface{}{op.Value}}, true, nil
+	}
+
+	for _, item := range items {
+		if len(op.Key) > 0 {
+			itemKeyVal, found := matchingValue(item, op.Key)
+			if found && reflect.DeepEqual(jsonPatchNormalize(itemKeyVal), jsonPatchNormalize(keyVal)) {
+				return ReplaceOp{}, false, nil
+			}
+		} else if reflect.DeepEqual(jsonPatchNormalize(item), jsonPatchNormalize(op.Value)) {
+			return ReplaceOp{}, false, nil
+		}
+	}
+
+	tokens := append(append([]Token{}, op.Path.Tokens()...), AfterLastIndexToken{})
+
+	return ReplaceOp{Path: NewPointer(tokens), Value: op.Value}, true, nil
+}
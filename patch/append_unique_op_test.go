@@ -0,0 +1,92 @@
+package patch_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/SUSE/go-patch/patch"
+)
+
+var _ = Describe("AppendUniqueOp.Apply", func() {
+	It("appends value only once", func() {
+		op := AppendUniqueOp{Path: MustNewPointerFromString("/tags"), Value: "b"}
+
+		res, err := op.Apply(parseYAML(`{"tags":["a"]}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(parseYAML(`{"tags":["a","b"]}`)))
+
+		res, err = op.Apply(res)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(parseYAML(`{"tags":["a","b"]}`)))
+	})
+
+	It("compares complex values regardless of map flavor and number types", func() {
+		doc := map[string]interface{}{
+			"items": []interface{}{map[string]interface{}{"name": "a", "size": float64(1)}},
+		}
+
+		res, err := AppendUniqueOp{
+			Path:  MustNewPointerFromString("/items"),
+			Value: parseYAML(`{"name":"a","size":1}`),
+		}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(map[string]interface{}{
+			"items": []interface{}{map[string]interface{}{"name": "a", "size": float64(1)}},
+		}))
+
+		res, err = AppendUniqueOp{
+			Path:  MustNewPointerFromString("/items"),
+			Value: parseYAML(`{"name":"a","size":2}`),
+		}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(map[string]interface{}{
+			"items": []interface{}{
+				map[string]interface{}{"name": "a", "size": float64(1)},
+				map[string]interface{}{"name": "a", "size": 2},
+			},
+		}))
+	})
+
+	It("leaves item with the same key value untouched if key is set", func() {
+		op := AppendUniqueOp{Path: MustNewPointerFromString("/items"), Value: parseYAML(`{"name":"a","size":2}`), Key: "name"}
+
+		res, err := op.Apply(parseYAML(`{"items":[{"name":"a","size":1}]}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(parseYAML(`{"items":[{"name":"a","size":1}]}`)))
+
+		res, err = op.Apply(parseYAML(`{"items":[{"name":"b"}]}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(parseYAML(`{"items":[{"name":"b"},{"name":"a","size":2}]}`)))
+	})
+
+	It("creates missing array if path is optional", func() {
+		res, err := AppendUniqueOp{Path: MustNewPointerFromString("/a?/tags"), Value: "b"}.Apply(parseYAML(`{}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(parseYAML(`{"a":{"tags":["b"]}}`)))
+	})
+
+	It("appends to every array matched by wildcard", func() {
+		res, err := AppendUniqueOp{Path: MustNewPointerFromString("/*/tags"), Value: "b"}.Apply(
+			parseYAML(`[{"tags":["a"]},{"tags":["b"]}]`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(parseYAML(`[{"tags":["a","b"]},{"tags":["b"]}]`)))
+	})
+
+	It("returns an error if array is missing and path is not optional", func() {
+		_, err := AppendUniqueOp{Path: MustNewPointerFromString("/tags"), Value: "b"}.Apply(parseYAML(`{}`))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find a map key 'tags' for path '/tags' (found no other map keys)"))
+	})
+
+	It("returns an error if it's not an array", func() {
+		_, err := AppendUniqueOp{Path: MustNewPointerFromString("/tags"), Value: "b"}.Apply(parseYAML(`{"tags":{}}`))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find an array at path '/tags' but found 'map[interface {}]interface {}'"))
+	})
+
+	It("returns an error if value does not have the key", func() {
+		_, err := AppendUniqueOp{Path: MustNewPointerFromString("/items"), Value: "b", Key: "name"}.Apply(parseYAML(`{"items":[]}`))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected value to have key 'name' for path '/items'"))
+	})
+})
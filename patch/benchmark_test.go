@@ -0,0 +1,134 @@
+package patch_test
+
+import (
+	"fmt"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+
+	. "github.com/SUSE/go-patch/patch"
+)
+
+// benchmarkDoc returns a manifest-like document with given number of instance groups
+func benchmarkDoc(size int) interface{} {
+	var igs []interface{}
+
+	for i := 0; i < size; i++ {
+		igs = append(igs, map[interface{}]interface{}{
+			"name":      fmt.Sprintf("ig-%d", i),
+			"instances": 1,
+			"azs":       []interface{}{"z1"},
+			"jobs": []interface{}{
+				map[interface{}]interface{}{"name": "nginx", "properties": map[interface{}]interface{}{"port": 80}},
+				map[interface{}]interface{}{"name": "syslog"},
+			},
+		})
+	}
+
+	return map[interface{}]interface{}{
+		"name":            "dep",
+		"releases":        []interface{}{map[interface{}]interface{}{"name": "nginx", "version": 1}},
+		"instance_groups": igs,
+	}
+}
+
+var benchmarkOpsStr = `
+- type: replace
+  path: /instance_groups/name=ig-10/instances
+  value: 3
+- type: replace
+  path: /instance_groups/name=ig-20/jobs/properties.port=80/properties/port
+  value: 8080
+- type: replace
+  path: /instance_groups/name~=ig-4[05]:all/azs/-
+  value: z2
+- type: replace
+  path: /instance_groups/name*=ig-3:optional/jobs/name=nginx:before
+  value: {name: bpm}
+- type: remove
+  path: /instance_groups/name=ig-31/jobs/name=syslog
+- type: replace
+  path: /releases/name=bpm?/version
+  value: 2
+- type: replace
+  path: /instance_groups/0:next/instances
+  value: 4
+- type: test
+  path: /name
+  value: dep
+`
+
+func benchmarkOps(b *testing.B) Ops {
+	var opDefs []OpDefinition
+
+	err := yaml.Unmarshal([]byte(benchmarkOpsStr), &opDefs)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	ops, err := NewOpsFromDefinitions(opDefs)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	return ops
+}
+
+func benchmarkApply(b *testing.B, size int, apply func(interface{}) (interface{}, error)) {
+	doc := benchmarkDoc(size)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, err := apply(doc)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkOpsApply50(b *testing.B)  { benchmarkApply(b, 50, benchmarkOps(b).Apply) }
+func BenchmarkOpsApply500(b *testing.B) { benchmarkApply(b, 500, benchmarkOps(b).Apply) }
+
+func BenchmarkOpsApplyCopyOnWrite50(b *testing.B) {
+	benchmarkApply(b, 50, benchmarkOps(b).ApplyCopyOnWrite)
+}
+
+func BenchmarkOpsApplyCopyOnWrite500(b *testing.B) {
+	benchmarkApply(b, 500, benchmarkOps(b).ApplyCopyOnWrite)
+}
+
+func benchmarkPlan(b *testing.B) Plan {
+	plan, err := Compile(benchmarkOps(b))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	return plan
+}
+
+func BenchmarkPlanApply50(b *testing.B)  { benchmarkApply(b, 50, benchmarkPlan(b).Apply) }
+func BenchmarkPlanApply500(b *testing.B) { benchmarkApply(b, 500, benchmarkPlan(b).Apply) }
+
+func BenchmarkPlanApplyCopyOnWrite50(b *testing.B) {
+	benchmarkApply(b, 50, benchmarkPlan(b).ApplyCopyOnWrite)
+}
+
+func BenchmarkPlanApplyCopyOnWrite500(b *testing.B) {
+	benchmarkApply(b, 500, benchmarkPlan(b).ApplyCopyOnWrite)
+}
+
+func BenchmarkCompile(b *testing.B) {
+	ops := benchmarkOps(b)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, err := Compile(ops)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
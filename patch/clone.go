@@ -0,0 +1,215 @@
+package patch
+
+import (
+	"fmt"
+	"reflect"
+
+	"gopkg.in/yaml.v2"
+)
+
+// cloneValue returns a deep copy of the value preserving its types
+// (e.g. structs, typed maps and arrays, int64) besides converting maps
+// produced by yaml and encoding/json libraries to the flavor. Values containing
+// types that cannot be copied (e.g. channels and functions) are cloned using yaml library.
+func (f docMapFlavor) cloneValue(in interface{}) (interface{}, error) {
+	if out, ok := f.copyValue(in); ok {
+		return out, nil
+	}
+
+	out, err := yamlCloneValue(in)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.Convert(out), nil
+}
+
+func (f docMapFlavor) copyValue(in interface{}) (interface{}, bool) {
+	switch typedIn := in.(type) {
+	case nil, bool, string, int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64, float32, float64:
+		return in, true
+
+	case map[interface{}]interface{}:
+		if f.strMaps {
+			out := make(map[string]interface{}, len(typedIn))
+			for k, v := range typedIn {
+				copiedV, ok := f.copyValue(v)
+				if !ok {
+					return nil, false
+				}
+				out[fmt.Sprintf("%v", k)] = copiedV
+			}
+			return out, true
+		}
+
+		out := make(map[interface{}]interface{}, len(typedIn))
+		for k, v := range typedIn {
+			copiedV, ok := f.copyValue(v)
+			if !ok {
+				return nil, false
+			}
+			out[k] = copiedV
+		}
+		return out, true
+
+	case map[string]interface{}:
+		if !f.strMaps {
+			out := make(map[interface{}]interface{}, len(typedIn))
+			for k, v := range typedIn {
+				copiedV, ok := f.copyValue(v)
+				if !ok {
+					return nil, false
+				}
+				out[k] = copiedV
+			}
+			return out, true
+		}
+
+		out := make(map[string]interface{}, len(typedIn))
+		for k, v := range typedIn {
+			copiedV, ok := f.copyValue(v)
+			if !ok {
+				return nil, false
+			}
+			out[k] = copiedV
+		}
+		return out, true
+
+	case []interface{}:
+		out := make([]interface{}, len(typedIn))
+		for i, v := range typedIn {
+			copiedV, ok := f.copyValue(v)
+			if !ok {
+				return nil, false
+			}
+			out[i] = copiedV
+		}
+		return out, true
+
+	default:
+		out, ok := copyReflectValue(reflect.ValueOf(in))
+		if !ok {
+			return nil, false
+		}
+		return out.Interface(), true
+	}
+}
+
+// copyReflectValue copies values of types other than the ones produced by yaml
+// and encoding/json libraries. Unexported struct fields are copied shallowly.
+func copyReflectValue(in reflect.Value) (reflect.Value, bool) {
+	switch in.Kind() {
+	case reflect.Bool, reflect.String, reflect.Complex64, reflect.Complex128,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Uintptr, reflect.Float32, reflect.Float64:
+		return in, true
+
+	case reflect.Map:
+		if in.IsNil() {
+			return in, true
+		}
+
+		out := reflect.MakeMapWithSize(in.Type(), in.Len())
+		iter := in.MapRange()
+
+		for iter.Next() {
+			copiedV, ok := copyReflectValue(iter.Value())
+			if !ok {
+				return in, false
+			}
+			out.SetMapIndex(iter.Key(), copiedV)
+		}
+
+		return out, true
+
+	case reflect.Slice:
+		if in.IsNil() {
+			return in, true
+		}
+
+		out := reflect.MakeSlice(in.Type(), in.Len(), in.Len())
+
+		return out, copyReflectItems(in, out)
+
+	case reflect.Array:
+		out := reflect.New(in.Type()).Elem()
+
+		return out, copyReflectItems(in, out)
+
+	case reflect.Ptr, reflect.Interface:
+		if in.IsNil() {
+			return in, true
+		}
+
+		copiedElem, ok := copyReflectValue(in.Elem())
+		if !ok {
+			return in, false
+		}
+
+		if in.Kind() == reflect.Ptr {
+			out := reflect.New(in.Type().Elem())
+			out.Elem().Set(copiedElem)
+			return out, true
+		}
+
+		out := reflect.New(in.Type()).Elem()
+		out.Set(copiedElem)
+
+		return out, true
+
+	case reflect.Struct:
+		out := reflect.New(in.Type()).Elem()
+		out.Set(in)
+
+		for i := 0; i < in.NumField(); i++ {
+			if in.Type().Field(i).PkgPath != "" {
+				continue // unexported
+			}
+
+			copiedField, ok := copyReflectValue(in.Field(i))
+			if !ok {
+				return in, false
+			}
+			out.Field(i).Set(copiedField)
+		}
+
+		return out, true
+
+	default:
+		// Channels, functions and unsafe pointers are only copied when nil
+		return in, in.Kind() != reflect.UnsafePointer && in.IsNil()
+	}
+}
+
+func copyReflectItems(in, out reflect.Value) bool {
+	for i := 0; i < in.Len(); i++ {
+		copiedItem, ok := copyReflectValue(in.Index(i))
+		if !ok {
+			return false
+		}
+		out.Index(i).Set(copiedItem)
+	}
+	return true
+}
+
+func yamlCloneValue(in interface{}) (out interface{}, err error) {
+	defer func() {
+		if recoverVal := recover(); recoverVal != nil {
+			err = fmt.Errorf("Recovered: %s", recoverVal)
+		}
+	}()
+
+	bytes, err := yaml.Marshal(in)
+	if err != nil {
+		return nil, err
+	}
+
+	err = yaml.Unmarshal(bytes, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
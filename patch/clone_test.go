@@ -0,0 +1,169 @@
+package patch_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v2"
+
+	. "github.com/SUSE/go-patch/patch"
+)
+
+type cloneTestStruct struct {
+	Name     string
+	Port     int64
+	Tags     []string
+	Labels   map[string]string
+	Nested   *cloneTestStruct
+	Any      interface{}
+	Callback func()
+
+	private map[string]string
+}
+
+var _ = Describe("Value cloning", func() {
+	replace := func(doc, value interface{}) interface{} {
+		res, err := ReplaceOp{Path: MustNewPointerFromString("/val?"), Value: value}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		val, err := FindOp{Path: MustNewPointerFromString("/val")}.Apply(res)
+		Expect(err).ToNot(HaveOccurred())
+
+		return val
+	}
+
+	yamlDoc := func() interface{} { return map[interface{}]interface{}{} }
+
+	It("preserves types of scalar values", func() {
+		for _, val := range []interface{}{
+			true, "str", int(1), int8(1), int16(1), int32(1), int64(1), uint(1), uint8(1),
+			uint16(1), uint32(1), uint64(1), float32(1), float64(1), complex64(1), time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		} {
+			Expect(replace(yamlDoc(), val)).To(Equal(val))
+		}
+	})
+
+	It("preserves structs and copies their exported fields deeply", func() {
+		private := map[string]string{"p": "1"}
+		val := cloneTestStruct{
+			Name:    "a",
+			Port:    8080,
+			Tags:    []string{"t1"},
+			Labels:  map[string]string{"l": "1"},
+			Nested:  &cloneTestStruct{Name: "b", Any: []interface{}{map[string]interface{}{"k": 1}}},
+			private: private,
+		}
+
+		res := replace(yamlDoc(), val)
+		Expect(res).To(Equal(val))
+
+		val.Tags[0] = "changed"
+		val.Labels["l"] = "changed"
+		val.Nested.Name = "changed"
+		val.Nested.Any.([]interface{})[0].(map[string]interface{})["k"] = 2
+
+		typedRes := res.(cloneTestStruct)
+		Expect(typedRes.Tags).To(Equal([]string{"t1"}))
+		Expect(typedRes.Labels).To(Equal(map[string]string{"l": "1"}))
+		Expect(typedRes.Nested.Name).To(Equal("b"))
+		Expect(typedRes.Nested.Any).To(Equal([]interface{}{map[string]interface{}{"k": 1}}))
+	})
+
+	It("preserves pointers, typed maps, arrays and slices", func() {
+		port := 80
+
+		for _, val := range []interface{}{
+			&port,
+			map[string]string{"a": "b"},
+			map[int][]int{1: {2, 3}},
+			[]string{"a", "b"},
+			[2]interface{}{"a", map[interface{}]interface{}{"b": "c"}},
+			[]string(nil),
+			(*int)(nil),
+		} {
+			Expect(replace(yamlDoc(), val)).To(Equal(val))
+		}
+
+		Expect(replace(yamlDoc(), &port)).ToNot(BeIdenticalTo(&port))
+	})
+
+	It("converts maps produced by yaml and encoding/json libraries to the flavor of the document", func() {
+		Expect(replace(yamlDoc(), map[string]interface{}{"a": []interface{}{map[string]interface{}{"b": float64(1)}}})).To(
+			Equal(map[interface{}]interface{}{"a": []interface{}{map[interface{}]interface{}{"b": float64(1)}}}))
+
+		Expect(replace(map[string]interface{}{}, map[interface{}]interface{}{"a": []interface{}{map[interface{}]interface{}{1: int64(1)}}})).To(
+			Equal(map[string]interface{}{"a": []interface{}{map[string]interface{}{"1": int64(1)}}}))
+	})
+
+	It("falls back to yaml library for values that cannot be copied", func() {
+		type withChan struct {
+			Name string
+			Ch   chan int `yaml:"-"`
+		}
+
+		Expect(replace(yamlDoc(), withChan{Name: "a", Ch: make(chan int)})).To(
+			Equal(map[interface{}]interface{}{"name": "a"}))
+
+		_, err := ReplaceOp{Path: MustNewPointerFromString("/val"), Value: func() {}}.Apply(yamlDoc())
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("ReplaceOp cloning value"))
+	})
+})
+
+func benchmarkCloneValue(size int) interface{} {
+	var items []interface{}
+
+	for i := 0; i < size; i++ {
+		items = append(items, map[interface{}]interface{}{
+			"name":       fmt.Sprintf("item-%d", i),
+			"instances":  i,
+			"azs":        []interface{}{"z1", "z2"},
+			"properties": map[interface{}]interface{}{"port": 8080, "enabled": true, "ratio": 0.5},
+		})
+	}
+
+	return map[interface{}]interface{}{"items": items}
+}
+
+func benchmarkReplaceOpClone(b *testing.B, size int) {
+	value := benchmarkCloneValue(size)
+	op := ReplaceOp{Path: MustNewPointerFromString("/val?"), Value: value}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, err := op.Apply(map[interface{}]interface{}{})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// benchmarkYAMLRoundTripClone measures previous approach of cloning values
+func benchmarkYAMLRoundTripClone(b *testing.B, size int) {
+	value := benchmarkCloneValue(size)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		bytes, err := yaml.Marshal(value)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		var out interface{}
+
+		err = yaml.Unmarshal(bytes, &out)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkReplaceOpClone10(b *testing.B)       { benchmarkReplaceOpClone(b, 10) }
+func BenchmarkReplaceOpClone1000(b *testing.B)     { benchmarkReplaceOpClone(b, 1000) }
+func BenchmarkYAMLRoundTripClone10(b *testing.B)   { benchmarkYAMLRoundTripClone(b, 10) }
+func BenchmarkYAMLRoundTripClone1000(b *testing.B) { benchmarkYAMLRoundTripClone(b, 1000) }
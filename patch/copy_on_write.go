@@ -0,0 +1,226 @@
+package patch
+
+import (
+	"reflect"
+)
+
+// ApplyCopyOnWrite applies operations without modifying given document: only maps and arrays
+// along modified paths are copied while the rest of the result is shared with given document.
+// Hence one document could be cheaply patched into many variants, including from multiple
+// goroutines concurrently (as long as nothing modifies it in place).
+//
+// Results share values with given document, hence they should only be modified
+// via Apply or ApplyCopyOnWrite as well. Operations of unknown types (e.g. registered via RegisterOpType)
+// are applied to a copy of the whole document.
+func (ops Ops) ApplyCopyOnWrite(doc interface{}) (interface{}, error) {
+	return copyOnWrite{owned: map[uintptr]struct{}{}}.applyOps(ops, doc)
+}
+
+// copyOnWrite keeps track of maps and arrays copied during single application
+// so that they are modified in place by following operations
+type copyOnWrite struct {
+	owned    map[uintptr]struct{}
+	matchers matchers
+}
+
+func (c copyOnWrite) applyOps(ops Ops, doc interface{}) (interface{}, error) {
+	var err error
+
+	for _, op := range ops {
+		doc, err = c.apply(op, doc)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return doc, nil
+}
+
+func (c copyOnWrite) apply(op Op, doc interface{}) (interface{}, error) {
+	var paths []Pointer
+
+	switch typedOp := op.(type) {
+	case Ops:
+		return c.applyOps(typedOp, doc)
+
+	case Plan:
+		return copyOnWrite{owned: c.owned, matchers: typedOp.matchers}.applyOps(typedOp.ops, doc)
+
+	case DescriptiveOp:
+		return DescriptiveOp{Op: copyOnWriteOp{typedOp.Op, c}, ErrorMsg: typedOp.ErrorMsg}.Apply(doc)
+
+	case FindOp, TestOp, JSONPatchTestOp, ErrOp:
+		return applyOp(op, doc, c.matchers)
+
+	case QMoveOp:
+		ops, err := typedOp.ops(doc, c.matchers)
+		if err != nil {
+			return nil, err
+		}
+		return c.applyOps(ops, doc)
+
+	case JSONPatchMoveOp:
+		ops, err := typedOp.ops(doc)
+		if err != nil {
+			return nil, err
+		}
+		return c.applyOps(ops, doc)
+
+	case QCopyOp:
+		paths = []Pointer{typedOp.Path}
+	case pathOp:
+		paths = []Pointer{typedOp.path()}
+
+	case JSONPatchAddOp:
+		paths = c.jsonPaths(typedOp.Path, doc, true)
+	case JSONPatchRemoveOp:
+		paths = c.jsonPaths(typedOp.Path, doc, false)
+	case JSONPatchReplaceOp:
+		paths = c.jsonPaths(typedOp.Path, doc, false)
+	case JSONPatchCopyOp:
+		paths = c.jsonPaths(typedOp.Path, doc, true)
+
+	default:
+		return op.Apply(copyDoc(doc))
+	}
+
+	for _, path := range paths {
+		doc = c.copyPath(doc, path)
+	}
+
+	return applyOp(op, doc, c.matchers)
+}
+
+// copyPath copies maps and arrays referred to by the path (including the last one if it exists)
+// and links them into the document. Path is followed as far as possible; errors are left
+// to be reported by the operation itself.
+func (c copyOnWrite) copyPath(doc interface{}, path Pointer) interface{} {
+	ptrs, err := path.expand(doc, c.matchers)
+	if err != nil {
+		return doc
+	}
+
+	doc = c.copy(doc)
+
+	for _, ptr := range ptrs {
+		if len(ptr.Tokens()) == 1 {
+			continue
+		}
+
+		w := walker{Path: ptr, Doc: copyOnWriteWalkDoc{ifaceWalkDoc{matchers: c.matchers}, c}, Missing: walkMissingStop, Insertion: true}
+
+		_ = w.Walk(doc, func(newDoc interface{}) { doc = newDoc }, func(loc walkLocation) error {
+			if loc.Found {
+				w.Doc.Child(loc)
+			}
+			return nil
+		})
+	}
+
+	return doc
+}
+
+func (c copyOnWrite) jsonPaths(path JSONPointer, doc interface{}, insertion bool) []Pointer {
+	ptr, err := path.Pointer(doc, insertion)
+	if err != nil {
+		return nil
+	}
+	return []Pointer{ptr}
+}
+
+// copy returns shallow copy of a map or an array unless it was already copied
+func (c copyOnWrite) copy(obj interface{}) interface{} {
+	switch typedObj := obj.(type) {
+	case map[interface{}]interface{}:
+		if c.isOwned(obj) {
+			return obj
+		}
+
+		result := make(map[interface{}]interface{}, len(typedObj))
+		for k, v := range typedObj {
+			result[k] = v
+		}
+
+		return c.own(result)
+
+	case map[string]interface{}:
+		if c.isOwned(obj) {
+			return obj
+		}
+
+		result := make(map[string]interface{}, len(typedObj))
+		for k, v := range typedObj {
+			result[k] = v
+		}
+
+		return c.own(result)
+
+	case []interface{}:
+		// Empty arrays are always copied since they cannot be told apart
+		if len(typedObj) > 0 && c.isOwned(obj) {
+			return obj
+		}
+
+		// Capacity is not preserved so that appending to a copy does not write into shared array
+		result := make([]interface{}, len(typedObj))
+		copy(result, typedObj)
+
+		return c.own(result)
+
+	default:
+		return obj
+	}
+}
+
+func (c copyOnWrite) own(obj interface{}) interface{} {
+	if id, ok := c.id(obj); ok {
+		c.owned[id] = struct{}{}
+	}
+	return obj
+}
+
+func (c copyOnWrite) isOwned(obj interface{}) bool {
+	id, ok := c.id(obj)
+	if !ok {
+		return false
+	}
+
+	_, found := c.owned[id]
+
+	return found
+}
+
+func (copyOnWrite) id(obj interface{}) (uintptr, bool) {
+	val := reflect.ValueOf(obj)
+
+	if val.Kind() == reflect.Slice && val.Len() == 0 {
+		return 0, false
+	}
+
+	return val.Pointer(), true
+}
+
+// copyOnWriteWalkDoc copies maps and arrays before following them
+type copyOnWriteWalkDoc struct {
+	ifaceWalkDoc
+	c copyOnWrite
+}
+
+func (d copyOnWriteWalkDoc) Child(loc walkLocation) (interface{}, func(interface{})) {
+	val, update := d.ifaceWalkDoc.Child(loc)
+
+	copiedVal := d.c.copy(val)
+	update(copiedVal)
+
+	return copiedVal, update
+}
+
+// copyOnWriteOp applies wrapped operation without modifying given document
+type copyOnWriteOp struct {
+	Op Op
+	c  copyOnWrite
+}
+
+func (op copyOnWriteOp) Apply(doc interface{}) (interface{}, error) {
+	return op.c.apply(op.Op, doc)
+}
@@ -0,0 +1,182 @@
+package patch_test
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/SUSE/go-patch/patch"
+)
+
+var _ = Describe("Ops.ApplyCopyOnWrite", func() {
+	var base, pristine interface{}
+
+	BeforeEach(func() {
+		docStr := `
+name: dep
+releases: [{name: b, version: 1}, {name: a, version: 2}]
+instance_groups:
+- name: web
+  instances: 1
+  jobs: [{name: nginx, properties: {port: 80}}]
+  tags: [x, y, x]
+- name: db
+  instances: 1
+  jobs: [{name: pg}]
+other: {nested: {deep: [1, 2]}}
+`
+		base = parseYAML(docStr)
+		pristine = parseYAML(docStr)
+	})
+
+	It("produces the same result as Apply without modifying given document", func() {
+		opsStrs := []string{
+			`[{type: replace, path: '/instance_groups/name=web/jobs/name=nginx/properties/port', value: 8080}]`,
+			`[{type: replace, path: '/instance_groups/name=api?/instances', value: 2}]`,
+			`[{type: replace, path: '/instance_groups/0:before', value: {name: first}}]`,
+			`[{type: replace, path: '/releases/-', value: {name: c}}]`,
+			`[{type: add, path: '/instance_groups/name=db/azs', value: [z1]}]`,
+			`[{type: default, path: '/instance_groups/*/instances', value: 3}]`,
+			`[{type: remove, path: '/instance_groups/name=web/jobs/0'}]`,
+			`[{type: remove, path: '/releases/*'}]`,
+			`[{type: merge, path: '/instance_groups/name=web', arrays: key=name, value: {jobs: [{name: nginx, properties: {tls: true}}]}}]`,
+			`[{type: qcopy, from: '/other', path: '/instance_groups/name=db/other?'}]`,
+			`[{type: qmove, from: '/instance_groups/name=web/jobs/0', path: '/instance_groups/name=db/jobs/-'}]`,
+			`[{type: append-unique, path: '/instance_groups/name=web/tags', value: z}]`,
+			`[{type: remove-value, path: '/instance_groups/name=web/tags', value: x}]`,
+			`[{type: dedupe, path: '/instance_groups/*/tags?'}]`,
+			`[{type: sort, path: '/releases', key: name}]`,
+			`[{type: reorder, path: '/instance_groups/name=db', index: 0}]`,
+			`[{type: upcase, path: '/instance_groups/name=db/name'}]`,
+			`[{type: test, path: '/name', value: dep}, {type: replace, path: '/name', value: dep2, error: custom}]`,
+			`[{type: remove, path: '/instance_groups/0'}, {type: replace, path: '/instance_groups/0/instances', value: 5}]`,
+		}
+
+		for _, opsStr := range opsStrs {
+			ops := parseOps(opsStr)
+
+			expected, err := ops.Apply(base)
+			Expect(err).ToNot(HaveOccurred(), opsStr)
+
+			res, err := ops.ApplyCopyOnWrite(base)
+			Expect(err).ToNot(HaveOccurred(), opsStr)
+			Expect(res).To(Equal(expected), opsStr)
+
+			Expect(base).To(Equal(pristine), opsStr)
+		}
+	})
+
+	It("supports JSON patch operations", func() {
+		ops := Ops{
+			JSONPatchMoveOp{From: MustNewJSONPointerFromString("/releases/0"), Path: MustNewJSONPointerFromString("/releases/-")},
+			JSONPatchAddOp{Path: MustNewJSONPointerFromString("/instance_groups/0/jobs/0/properties/tls"), Value: true},
+			JSONPatchCopyOp{From: MustNewJSONPointerFromString("/name"), Path: MustNewJSONPointerFromString("/other/name")},
+			JSONPatchRemoveOp{Path: MustNewJSONPointerFromString("/other/nested/deep/0")},
+			JSONPatchReplaceOp{Path: MustNewJSONPointerFromString("/instance_groups/1/instances"), Value: 2},
+		}
+
+		expected, err := ops.Apply(base)
+		Expect(err).ToNot(HaveOccurred())
+
+		res, err := ops.ApplyCopyOnWrite(base)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(expected))
+
+		Expect(base).To(Equal(pristine))
+	})
+
+	It("shares unmodified parts of the document", func() {
+		res, err := parseOps(`[{type: replace, path: '/instance_groups/name=db/instances', value: 2}]`).ApplyCopyOnWrite(base)
+		Expect(err).ToNot(HaveOccurred())
+
+		resMap := res.(map[interface{}]interface{})
+		baseMap := base.(map[interface{}]interface{})
+
+		same := func(a, b interface{}) bool { return reflect.ValueOf(a).Pointer() == reflect.ValueOf(b).Pointer() }
+
+		Expect(same(resMap, baseMap)).To(BeFalse())
+		Expect(same(resMap["other"], baseMap["other"])).To(BeTrue())
+		Expect(same(resMap["releases"], baseMap["releases"])).To(BeTrue())
+		Expect(same(resMap["instance_groups"], baseMap["instance_groups"])).To(BeFalse())
+
+		resIGs := resMap["instance_groups"].([]interface{})
+		baseIGs := baseMap["instance_groups"].([]interface{})
+
+		Expect(same(resIGs[0], baseIGs[0])).To(BeTrue())
+		Expect(same(resIGs[1], baseIGs[1])).To(BeFalse())
+	})
+
+	It("does not write into shared arrays with spare capacity", func() {
+		items := make([]interface{}, 1, 10)
+		items[0] = 1
+
+		doc := map[interface{}]interface{}{"items": items}
+		ops := func(val int) Ops { return Ops{ReplaceOp{Path: MustNewPointerFromString("/items/-"), Value: val}} }
+
+		res1, err := ops(2).ApplyCopyOnWrite(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		res2, err := ops(3).ApplyCopyOnWrite(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res1).To(Equal(map[interface{}]interface{}{"items": []interface{}{1, 2}}))
+		Expect(res2).To(Equal(map[interface{}]interface{}{"items": []interface{}{1, 3}}))
+	})
+
+	It("leaves given document untouched if any operation errors", func() {
+		_, err := parseOps(`
+- type: replace
+  path: /instance_groups/name=web/instances
+  value: 2
+- type: sort
+  path: /releases
+  key: name
+- type: replace
+  path: /missing/key
+  value: 1
+  error: custom-error
+`).ApplyCopyOnWrite(base)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Error 'custom-error'"))
+
+		Expect(base).To(Equal(pristine))
+	})
+
+	It("allows patching the same document from multiple goroutines", func() {
+		var wg sync.WaitGroup
+
+		results := make([]interface{}, 20)
+		errs := make([]error, 20)
+
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+
+			go func(i int) {
+				defer wg.Done()
+
+				results[i], errs[i] = Ops{
+					ReplaceOp{Path: MustNewPointerFromString("/instance_groups/name=web/instances"), Value: i},
+					ReplaceOp{Path: MustNewPointerFromString("/releases/-"), Value: map[interface{}]interface{}{"name": fmt.Sprintf("r%d", i)}},
+					SortOp{Path: MustNewPointerFromString("/releases"), Key: "name"},
+					RemoveOp{Path: MustNewPointerFromString("/other/nested/deep/0")},
+				}.ApplyCopyOnWrite(base)
+			}(i)
+		}
+
+		wg.Wait()
+
+		for i := 0; i < 20; i++ {
+			Expect(errs[i]).ToNot(HaveOccurred())
+
+			res := results[i].(map[interface{}]interface{})
+			Expect(FindOp{Path: MustNewPointerFromString("/instance_groups/0/instances")}.Apply(res)).To(Equal(i))
+			Expect(FindOp{Path: MustNewPointerFromString("/releases/2/name")}.Apply(res)).To(Equal(fmt.Sprintf("r%d", i)))
+			Expect(FindOp{Path: MustNewPointerFromString("/other/nested/deep")}.Apply(res)).To(Equal([]interface{}{2}))
+		}
+
+		Expect(base).To(Equal(pristine))
+	})
+})
@@ -6,10 +6,14 @@ type QCopyOp struct {
 }
 
 func (op QCopyOp) Apply(doc interface{}) (interface{}, error) {
-	value, err := FindOp{Path: op.From}.Apply(doc)
+	return op.apply(doc, nil)
+}
+
+func (op QCopyOp) apply(doc interface{}, m matchers) (interface{}, error) {
+	value, err := FindOp{Path: op.From}.apply(doc, m)
 	if err != nil {
 		return nil, err
 	}
 
-	return ReplaceOp{Path: op.Path, Value: value}.Apply(doc)
+	return ReplaceOp{Path: op.Path, Value: value}.apply(doc, m)
 }
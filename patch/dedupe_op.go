@@ -0,0 +1,78 @@
+package patch
+
+import (
+	"reflect"
+)
+
+// DedupeOp removes array items that are equal to one of the preceding items
+// (or have the same value of Key, e.g. 'name', if Key is set), keeping first occurrences.
+// Nothing is removed if array is optional and missing.
+type DedupeOp struct {
+	Path Pointer
+	Key  string
+}
+
+func (op DedupeOp) path() Pointer           { return op.Path }
+func (op DedupeOp) withPath(ptr Pointer) Op { op.Path = ptr; return op }
+
+func (op DedupeOp) Apply(doc interface{}) (interface{}, error) {
+	return op.apply(doc, nil)
+}
+
+func (op DedupeOp) apply(doc interface{}, m matchers) (interface{}, error) {
+	if res, found, err := applyWildcard(op, doc, m); found {
+		return res, err
+	}
+
+	ops, err := op.removeOps(doc, m)
+	if err != nil {
+		return nil, err
+	}
+
+	return ops.apply(doc, m)
+}
+
+// removeOps returns operations that remove duplicate items from last to first
+func (op DedupeOp) removeOps(doc interface{}, m matchers) (Ops, error) {
+	loc, items, err := findArray(op.Path, doc, m)
+	if err != nil {
+		return nil, err
+	}
+
+	var seen []interface{}
+
+	ops := Ops{}
+
+	for i, item := range items {
+		val := item
+
+		if len(op.Key) > 0 {
+			var found bool
+
+			val, found = matchingValue(item, op.Key)
+			if !found {
+				continue
+			}
+		}
+
+		val = jsonPatchNormalize(val)
+
+		if op.contains(seen, val) {
+			tokens := append(append([]Token{}, loc.tokens...), IndexToken{Index: i})
+			ops = append(Ops{RemoveOp{Path: NewPointer(tokens)}}, ops...)
+		} else {
+			seen = append(seen, val)
+		}
+	}
+
+	return ops, nil
+}
+
+func (DedupeOp) contains(vals []interface{}, val interface{}) bool {
+	for _, v := range vals {
+		if reflect.DeepEqual(v, val) {
+			return true
+		}
+	}
+	return false
+}
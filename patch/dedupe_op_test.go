@@ -0,0 +1,42 @@
+package patch_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/SUSE/go-patch/patch"
+)
+
+var _ = Describe("DedupeOp.Apply", func() {
+	It("removes items equal to preceding items", func() {
+		res, err := DedupeOp{Path: MustNewPointerFromString("/a")}.Apply(
+			parseYAML(`{"a":["b",1,"b",{"x":1},1.0,{"x":1},"c"]}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(parseYAML(`{"a":["b",1,{"x":1},"c"]}`)))
+	})
+
+	It("removes items with the same key value keeping first occurrences", func() {
+		res, err := DedupeOp{Path: MustNewPointerFromString("/releases"), Key: "name"}.Apply(
+			parseYAML(`{"releases":[{"name":"a","v":1},{"v":2},{"name":"b"},{"name":"a","v":3},{"v":2}]}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(parseYAML(`{"releases":[{"name":"a","v":1},{"v":2},{"name":"b"},{"v":2}]}`)))
+	})
+
+	It("dedupes every array matched by wildcard", func() {
+		res, err := DedupeOp{Path: MustNewPointerFromString("/*")}.Apply(parseYAML(`[["a","a"],[1,2,1]]`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(parseYAML(`[["a"],[1,2]]`)))
+	})
+
+	It("does nothing if optional array is missing", func() {
+		res, err := DedupeOp{Path: MustNewPointerFromString("/a?")}.Apply(parseYAML(`{}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(parseYAML(`{}`)))
+	})
+
+	It("returns an error if it's not an array", func() {
+		_, err := DedupeOp{Path: MustNewPointerFromString("/a")}.Apply(parseYAML(`{"a":"b"}`))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find an array at path '/a' but found 'string'"))
+	})
+})
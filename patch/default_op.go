@@ -0,0 +1,35 @@
+package patch
+
+// DefaultOp sets value following ReplaceOp rules only if location does not exist yet,
+// leaving existing values (including nulls) untouched. Last map key does not
+// need to be optional; missing parents are created if they are optional.
+type DefaultOp struct {
+	Path  Pointer
+	Value interface{} // will be deep copied (see docMapFlavor.cloneValue)
+}
+
+func (op DefaultOp) path() Pointer           { return op.Path }
+func (op DefaultOp) withPath(ptr Pointer) Op { op.Path = ptr; return op }
+
+func (op DefaultOp) Apply(doc interface{}) (interface{}, error) {
+	return op.apply(doc, nil)
+}
+
+func (op DefaultOp) apply(doc interface{}, m matchers) (interface{}, error) {
+	if res, found, err := applyWildcard(op, doc, m); found {
+		return res, err
+	}
+
+	if len(op.Path.Tokens()) == 1 {
+		if doc != nil {
+			return doc, nil
+		}
+		return ReplaceOp{Path: op.Path, Value: op.Value}.apply(doc, m)
+	}
+
+	return ReplaceOp{Path: op.Path.lastKeyOptional(), Value: op.Value}.set(doc, m, op.checkMissing)
+}
+
+func (DefaultOp) checkMissing(loc walkLocation) (bool, error) {
+	return !loc.Found, nil
+}
@@ -0,0 +1,105 @@
+package patch_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/SUSE/go-patch/patch"
+)
+
+var _ = Describe("DefaultOp.Apply", func() {
+	It("sets document only if there is no document", func() {
+		res, err := DefaultOp{Path: MustNewPointerFromString(""), Value: "b"}.Apply(nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal("b"))
+
+		res, err = DefaultOp{Path: MustNewPointerFromString(""), Value: "b"}.Apply("a")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal("a"))
+	})
+
+	Describe("map key", func() {
+		It("sets missing map key", func() {
+			doc := map[interface{}]interface{}{"abc": 123}
+
+			res, err := DefaultOp{Path: MustNewPointerFromString("/xyz"), Value: 456}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{"abc": 123, "xyz": 456}))
+		})
+
+		It("leaves existing map key untouched", func() {
+			doc := map[interface{}]interface{}{"abc": 123, "xyz": nil}
+
+			for _, path := range []string{"/abc", "/abc?", "/xyz"} {
+				res, err := DefaultOp{Path: MustNewPointerFromString(path), Value: 456}.Apply(doc)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(res).To(Equal(map[interface{}]interface{}{"abc": 123, "xyz": nil}))
+			}
+		})
+
+		It("creates missing parents if they are optional", func() {
+			res, err := DefaultOp{Path: MustNewPointerFromString("/abc?/xyz"), Value: 456}.Apply(map[interface{}]interface{}{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{
+				"abc": map[interface{}]interface{}{"xyz": 456},
+			}))
+		})
+
+		It("returns an error if parent does not exist", func() {
+			_, err := DefaultOp{Path: MustNewPointerFromString("/abc/xyz"), Value: 456}.Apply(map[interface{}]interface{}{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected to find a map key 'abc' for path '/abc' (found no other map keys)"))
+		})
+	})
+
+	Describe("array item", func() {
+		It("leaves existing array item untouched", func() {
+			res, err := DefaultOp{Path: MustNewPointerFromString("/0"), Value: 10}.Apply([]interface{}{1, 2})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{1, 2}))
+		})
+
+		It("appends missing matching item", func() {
+			doc := []interface{}{map[interface{}]interface{}{"name": "a", "val": 1}}
+
+			res, err := DefaultOp{Path: MustNewPointerFromString("/name=a?/val"), Value: 10}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{
+				map[interface{}]interface{}{"name": "a", "val": 1},
+			}))
+
+			res, err = DefaultOp{Path: MustNewPointerFromString("/name=b?/val"), Value: 10}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{
+				map[interface{}]interface{}{"name": "a", "val": 1},
+				map[interface{}]interface{}{"name": "b", "val": 10},
+			}))
+		})
+	})
+
+	It("sets values for every item with wildcards", func() {
+		doc := []interface{}{
+			map[interface{}]interface{}{"name": "a"},
+			map[interface{}]interface{}{"name": "b", "val": 1},
+		}
+
+		res, err := DefaultOp{Path: MustNewPointerFromString("/*/val"), Value: 10}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal([]interface{}{
+			map[interface{}]interface{}{"name": "a", "val": 10},
+			map[interface{}]interface{}{"name": "b", "val": 1},
+		}))
+	})
+
+	It("keeps values set by earlier operations", func() {
+		ops := Ops{
+			ReplaceOp{Path: MustNewPointerFromString("/port?"), Value: 8080},
+			DefaultOp{Path: MustNewPointerFromString("/port"), Value: 80},
+			DefaultOp{Path: MustNewPointerFromString("/host"), Value: "localhost"},
+		}
+
+		res, err := ops.Apply(map[string]interface{}{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(map[string]interface{}{"port": 8080, "host": "localhost"}))
+	})
+})
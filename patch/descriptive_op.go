@@ -10,9 +10,13 @@ type DescriptiveOp struct {
 }
 
 func (op DescriptiveOp) Apply(doc interface{}) (interface{}, error) {
-	doc, err := op.Op.Apply(doc)
+	return op.apply(doc, nil)
+}
+
+func (op DescriptiveOp) apply(doc interface{}, m matchers) (interface{}, error) {
+	doc, err := applyOp(op.Op, doc, m)
 	if err != nil {
-		return nil, fmt.Errorf("Error '%s': %s", op.ErrorMsg, err.Error())
+		return nil, fmt.Errorf("Error '%s': %w", op.ErrorMsg, err)
 	}
 	return doc, nil
 }
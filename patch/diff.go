@@ -12,6 +12,10 @@ type Diff struct {
 	Left      interface{}
 	Right     interface{}
 	Unchecked bool
+
+	// Defaults expresses added map keys as default operations
+	// so that values set by other operations are not overwritten
+	Defaults bool
 }
 
 func (d Diff) Calculate() Ops {
@@ -30,6 +34,14 @@ func (d Diff) Calculate() Ops {
 }
 
 func (d Diff) calculate(left, right interface{}, tokens []Token) []Op {
+	// Both map flavors (e.g. from encoding/json) are compared as regular maps
+	if leftMap, ok := newDocMap(left); ok {
+		left = leftMap.IfaceMap()
+	}
+	if rightMap, ok := newDocMap(right); ok {
+		right = rightMap.IfaceMap()
+	}
+
 	switch typedLeft := left.(type) {
 	case map[interface{}]interface{}:
 		if typedRight, ok := right.(map[interface{}]interface{}); ok {
@@ -63,6 +75,10 @@ func (d Diff) calculate(left, right interface{}, tokens []Token) []Op {
 				} else { // add new
 					testOpTokens := append([]Token{}, newTokens...)
 					testOpTokens = append(testOpTokens, KeyToken{Key: fmt.Sprintf("%s", k)})
+					if d.Defaults {
+						ops = append(ops, DefaultOp{Path: NewPointer(testOpTokens), Value: typedRight[k]})
+						continue
+					}
 					newTokens = append(newTokens, KeyToken{Key: fmt.Sprintf("%s", k), Optional: true})
 					ops = append(ops,
 						TestOp{Path: NewPointer(testOpTokens), Absent: true},
@@ -55,6 +55,30 @@ var _ = Describe("Diff.Calculate", func() {
 		Expect(result).To(Equal(right))
 	})
 
+	It("can express added map keys as default operations", func() {
+		left := map[interface{}]interface{}{
+			"a": 123,
+			"b": map[interface{}]interface{}{"c": 456},
+		}
+		right := map[interface{}]interface{}{
+			"a": 124,
+			"b": map[interface{}]interface{}{"c": 456, "d": 789},
+			"e": []interface{}{1},
+		}
+
+		diffOps := Diff{Left: left, Right: right, Defaults: true}.Calculate()
+		Expect(diffOps).To(Equal(Ops{
+			TestOp{Path: MustNewPointerFromString("/a"), Value: 123},
+			ReplaceOp{Path: MustNewPointerFromString("/a"), Value: 124},
+			DefaultOp{Path: MustNewPointerFromString("/b/d"), Value: 789},
+			DefaultOp{Path: MustNewPointerFromString("/e"), Value: []interface{}{1}},
+		}))
+
+		result, err := diffOps.Apply(left)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(Equal(right))
+	})
+
 	It("can replace doc root with nil", func() {
 		testDiff("a", nil, []Op{
 			TestOp{Path: MustNewPointerFromString(""), Value: "a"},
@@ -0,0 +1,185 @@
+package patch
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Documents may contain maps produced by yaml library (map[interface{}]interface{})
+// or by encoding/json library (map[string]interface{}). docMap provides
+// access to either of them; maps created by operations use the same flavor
+// as the document that is being modified.
+type docMap struct {
+	ifaceMap map[interface{}]interface{}
+	strMap   map[string]interface{}
+}
+
+func newDocMap(obj interface{}) (docMap, bool) {
+	switch typedObj := obj.(type) {
+	case map[interface{}]interface{}:
+		return docMap{ifaceMap: typedObj}, true
+	case map[string]interface{}:
+		return docMap{strMap: typedObj}, true
+	default:
+		return docMap{}, false
+	}
+}
+
+func (m docMap) Get(key string) (interface{}, bool) {
+	if m.strMap != nil {
+		val, found := m.strMap[key]
+		return val, found
+	}
+	val, found := m.ifaceMap[key]
+	return val, found
+}
+
+func (m docMap) Set(key string, val interface{}) {
+	if m.strMap != nil {
+		m.strMap[key] = val
+	} else {
+		m.ifaceMap[key] = val
+	}
+}
+
+func (m docMap) Delete(key string) {
+	if m.strMap != nil {
+		delete(m.strMap, key)
+	} else {
+		delete(m.ifaceMap, key)
+	}
+}
+
+// Keys returns sorted string keys (other keys cannot be referred to by tokens)
+func (m docMap) Keys() []string {
+	var keys []string
+	if m.strMap != nil {
+		for k := range m.strMap {
+			keys = append(keys, k)
+		}
+	} else {
+		for k := range m.ifaceMap {
+			if str, ok := k.(string); ok {
+				keys = append(keys, str)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// IfaceMap returns map itself or a shallow copy of map[string]interface{}
+// (useful for errors such as OpMissingMapKeyErr)
+func (m docMap) IfaceMap() map[interface{}]interface{} {
+	if m.strMap == nil {
+		return m.ifaceMap
+	}
+	obj := map[interface{}]interface{}{}
+	for k, v := range m.strMap {
+		obj[k] = v
+	}
+	return obj
+}
+
+// docMapFlavor determines which map type should be used for new maps
+type docMapFlavor struct {
+	strMaps bool
+}
+
+// newDocMapFlavor picks flavor of the first map found in the document,
+// defaulting to map[interface{}]interface{} used by yaml library
+func newDocMapFlavor(doc interface{}) docMapFlavor {
+	switch typedDoc := doc.(type) {
+	case map[interface{}]interface{}:
+		return docMapFlavor{}
+	case map[string]interface{}:
+		return docMapFlavor{strMaps: true}
+	case []interface{}:
+		for _, item := range typedDoc {
+			switch item.(type) {
+			case map[interface{}]interface{}, map[string]interface{}, []interface{}:
+				return newDocMapFlavor(item)
+			}
+		}
+	}
+	return docMapFlavor{}
+}
+
+func (f docMapFlavor) NewMap() interface{} {
+	if f.strMaps {
+		return map[string]interface{}{}
+	}
+	return map[interface{}]interface{}{}
+}
+
+// NewMatchingItem creates a map that satisfies all token conditions
+func (f docMapFlavor) NewMatchingItem(token MatchingIndexToken) interface{} {
+	obj := f.NewMap()
+	for _, cond := range token.Conditions() {
+		f.setNested(obj, cond.Key, newMatchingValue(cond.Value))
+	}
+	return obj
+}
+
+// setNested sets value under dotted key (e.g. 'properties.port'), creating nested maps
+func (f docMapFlavor) setNested(obj interface{}, key string, val interface{}) {
+	m, _ := newDocMap(obj)
+
+	pieces := strings.SplitN(key, ".", 2)
+	if len(pieces) == 1 {
+		m.Set(key, val)
+		return
+	}
+
+	child, found := m.Get(pieces[0])
+	if _, ok := newDocMap(child); !found || !ok {
+		child = f.NewMap()
+		m.Set(pieces[0], child)
+	}
+
+	f.setNested(child, pieces[1], val)
+}
+
+// Convert returns a copy of the value with all maps converted to the flavor
+func (f docMapFlavor) Convert(in interface{}) interface{} {
+	switch typedIn := in.(type) {
+	case map[interface{}]interface{}:
+		if f.strMaps {
+			out := map[string]interface{}{}
+			for k, v := range typedIn {
+				out[fmt.Sprintf("%v", k)] = f.Convert(v)
+			}
+			return out
+		}
+		out := map[interface{}]interface{}{}
+		for k, v := range typedIn {
+			out[k] = f.Convert(v)
+		}
+		return out
+
+	case map[string]interface{}:
+		if f.strMaps {
+			out := map[string]interface{}{}
+			for k, v := range typedIn {
+				out[k] = f.Convert(v)
+			}
+			return out
+		}
+		out := map[interface{}]interface{}{}
+		for k, v := range typedIn {
+			out[k] = f.Convert(v)
+		}
+		return out
+
+	case []interface{}:
+		out := make([]interface{}, len(typedIn))
+		for i, v := range typedIn {
+			out[i] = f.Convert(v)
+		}
+		return out
+
+	default:
+		return in
+	}
+}
@@ -0,0 +1,184 @@
+package patch_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/SUSE/go-patch/patch"
+)
+
+var _ = Describe("Operations on documents decoded by encoding/json", func() {
+	Describe("ReplaceOp", func() {
+		It("replaces map keys and array items", func() {
+			doc := parseJSON(`{"abc":{"xyz":["a",{"name":"b","v":1}]}}`)
+
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/abc/xyz/name=b/v"), Value: 2}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[string]interface{}{
+				"abc": map[string]interface{}{
+					"xyz": []interface{}{"a", map[string]interface{}{"name": "b", "v": 2}},
+				},
+			}))
+		})
+
+		It("creates missing maps of the same flavor as the document", func() {
+			doc := parseJSON(`{"abc":[]}`)
+
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/xyz?/def?/name=c?/v"), Value: 1}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[string]interface{}{
+				"abc": []interface{}{},
+				"xyz": map[string]interface{}{
+					"def": []interface{}{map[string]interface{}{"name": "c", "v": 1}},
+				},
+			}))
+		})
+
+		It("converts maps within value to the flavor of the document", func() {
+			doc := parseJSON(`{"abc":1}`)
+
+			value := map[interface{}]interface{}{"def": []interface{}{map[interface{}]interface{}{"a": "b"}}}
+
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/abc"), Value: value}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[string]interface{}{
+				"abc": map[string]interface{}{"def": []interface{}{map[string]interface{}{"a": "b"}}},
+			}))
+
+			// Value itself is left untouched
+			Expect(value).To(Equal(map[interface{}]interface{}{"def": []interface{}{map[interface{}]interface{}{"a": "b"}}}))
+		})
+
+		It("returns an error if key is missing", func() {
+			doc := parseJSON(`{"abc":1}`)
+
+			_, err := ReplaceOp{Path: MustNewPointerFromString("/xyz/def"), Value: 1}.Apply(doc)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(
+				"Expected to find a map key 'xyz' for path '/xyz' (found map keys: 'abc')"))
+		})
+	})
+
+	Describe("RemoveOp", func() {
+		It("removes map keys and array items", func() {
+			doc := parseJSON(`{"abc":{"xyz":["a",{"name":"b"}]},"def":1}`)
+
+			res, err := RemoveOp{Path: MustNewPointerFromString("/abc/xyz/name=b")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+
+			res, err = RemoveOp{Path: MustNewPointerFromString("/def")}.Apply(res)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(res).To(Equal(map[string]interface{}{
+				"abc": map[string]interface{}{"xyz": []interface{}{"a"}},
+			}))
+		})
+	})
+
+	Describe("FindOp", func() {
+		It("finds nested values", func() {
+			doc := parseJSON(`{"abc":{"xyz":["a",{"name":"b","v":true}]}}`)
+
+			res, err := FindOp{Path: MustNewPointerFromString("/abc/xyz/name=b/v")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(true))
+
+			res, err = FindOp{Path: MustNewPointerFromString("/abc/missing?/v")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect([]interface{}{res}).To(Equal([]interface{}{nil}))
+		})
+	})
+
+	Describe("TestOp", func() {
+		It("compares maps regardless of their flavor", func() {
+			doc := parseJSON(`{"abc":{"xyz":["a"]}}`)
+
+			_, err := TestOp{
+				Path:  MustNewPointerFromString("/abc"),
+				Value: map[interface{}]interface{}{"xyz": []interface{}{"a"}},
+			}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = TestOp{
+				Path:  MustNewPointerFromString("/abc"),
+				Value: map[string]interface{}{"xyz": []interface{}{"b"}},
+			}.Apply(doc)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Found value does not match expected value"))
+		})
+
+		It("compares numbers regardless of their type", func() {
+			doc := parseJSON(`{"a":1,"abc":{"xyz":[1,{"port":8080}]}}`)
+
+			_, err := TestOp{Path: MustNewPointerFromString("/a"), Value: 1}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = TestOp{
+				Path:  MustNewPointerFromString("/abc"),
+				Value: parseYAML("xyz: [1, {port: 8080}]"),
+			}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = TestOp{Path: MustNewPointerFromString("/abc/xyz/1/port"), Value: 80}.Apply(doc)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Found value does not match expected value"))
+		})
+
+		It("checks absence of keys", func() {
+			doc := parseJSON(`{"abc":{}}`)
+
+			_, err := TestOp{Path: MustNewPointerFromString("/abc/xyz"), Absent: true}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	Describe("MergeOp", func() {
+		It("merges maps and keeps document's flavor", func() {
+			doc := parseJSON(`{"abc":{"a":"b","c":{"d":"e"}}}`)
+			patch := parseJSON(`{"c":{"d":null,"f":"g"}}`)
+
+			res, err := MergeOp{Path: MustNewPointerFromString("/abc"), Value: patch}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[string]interface{}{
+				"abc": map[string]interface{}{"a": "b", "c": map[string]interface{}{"f": "g"}},
+			}))
+		})
+	})
+
+	Describe("Diff", func() {
+		It("calculates operations that turn left document into right document", func() {
+			left := parseJSON(`{"a":"b","c":[{"d":1}],"e":"f"}`)
+			right := parseJSON(`{"a":"x","c":[{"d":1},{"g":"h"}]}`)
+
+			ops := Diff{Left: left, Right: right}.Calculate()
+
+			res, err := ops.Apply(left)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(right))
+		})
+
+		It("treats maps of different flavors with same contents as equal", func() {
+			left := parseJSON(`{"a":{"b":"c"}}`)
+			right := map[interface{}]interface{}{"a": map[interface{}]interface{}{"b": "c"}}
+
+			Expect(Diff{Left: left, Right: right}.Calculate()).To(Equal(Ops{}))
+		})
+	})
+
+	Describe("JSON patch", func() {
+		It("applies operations", func() {
+			doc := parseJSON(`{"foo":["bar","baz"]}`)
+
+			res, err := Ops{
+				JSONPatchAddOp{Path: MustNewJSONPointerFromString("/foo/1"), Value: "qux"},
+				JSONPatchAddOp{Path: MustNewJSONPointerFromString("/abc"), Value: map[interface{}]interface{}{"a": 1}},
+				JSONPatchRemoveOp{Path: MustNewJSONPointerFromString("/foo/0")},
+			}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[string]interface{}{
+				"foo": []interface{}{"qux", "baz"},
+				"abc": map[string]interface{}{"a": 1},
+			}))
+		})
+	})
+})
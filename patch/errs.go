@@ -77,3 +77,50 @@ type OpUnexpectedTokenErr struct {
 func (e OpUnexpectedTokenErr) Error() string {
 	return fmt.Sprintf("Expected to not find token '%T' at path '%s'", e.Token, e.Path)
 }
+
+type OpExistingMapKeyErr struct {
+	Key  string
+	Path Pointer
+}
+
+func (e OpExistingMapKeyErr) Error() string {
+	errMsg := "Expected to not find a map key '%s' for path '%s' (use replace operation to overwrite it)"
+	return fmt.Sprintf(errMsg, e.Key, e.Path)
+}
+
+type OpExistingIndexErr struct {
+	Idx  int
+	Path Pointer
+}
+
+func (e OpExistingIndexErr) Error() string {
+	errMsg := "Expected to not find array index '%d' for path '%s' (use '-', ':before' or ':after' to insert array items)"
+	return fmt.Sprintf(errMsg, e.Idx, e.Path)
+}
+
+// OpErr is an error of a single operation applied by Ops.ApplyCollectingErrs
+type OpErr struct {
+	Index int // position of the operation within applied operations
+	Op    Op
+	Err   error
+}
+
+func (e OpErr) Error() string {
+	return fmt.Sprintf("Operation [%d]: %s within\n%s", e.Index, e.Err, fmtOp(e.Op))
+}
+
+func (e OpErr) Unwrap() error { return e.Err }
+
+// MultiOpErr lists errors of all failed operations in order
+type MultiOpErr struct {
+	Errs []OpErr
+}
+
+func (e MultiOpErr) Error() string {
+	var errStrs []string
+	for _, err := range e.Errs {
+		errStrs = append(errStrs, err.Error())
+	}
+	errMsg := "Expected all operations to succeed but %d failed:\n\n%s"
+	return fmt.Sprintf(errMsg, len(e.Errs), strings.Join(errStrs, "\n\n"))
+}
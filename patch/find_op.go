@@ -8,116 +8,94 @@ type FindOp struct {
 	Path Pointer
 }
 
+// Apply returns found value; if path contains wildcard tokens,
+// a list of values found at every matched location is returned instead.
 func (op FindOp) Apply(doc interface{}) (interface{}, error) {
+	return op.apply(doc, nil)
+}
+
+func (op FindOp) apply(doc interface{}, m matchers) (interface{}, error) {
+	ptrs, found, err := wildcardPointers(op.Path, wildcardFind(doc, m), m)
+	if found {
+		if err != nil {
+			return nil, err
+		}
+		return op.findAll(ptrs, doc, m)
+	}
+
 	tokens := op.Path.Tokens()
 
 	if len(tokens) == 1 {
 		return doc, nil
 	}
 
-	obj := doc
+	for _, token := range tokens {
+		if _, ok := token.(AfterLastIndexToken); ok {
+			errMsg := "Expected not to find after last index token in path '%s' (not supported in find operations)"
+			return nil, fmt.Errorf(errMsg, op.Path)
+		}
+	}
 
-	for i, token := range tokens[1:] {
-		isLast := i == len(tokens)-2
-		currPath := NewPointer(tokens[:i+2])
+	var result interface{}
 
-		switch typedToken := token.(type) {
-		case IndexToken:
-			typedObj, ok := obj.([]interface{})
-			if !ok {
-				return nil, NewOpArrayMismatchTypeErr(currPath, obj)
-			}
+	flavor := newDocMapFlavor(doc)
+	w := walker{Path: op.Path, Doc: ifaceWalkDoc{flavor, m}, Missing: walkMissingDetach}
 
-			idx, err := ArrayIndex{Index: typedToken.Index, Modifiers: typedToken.Modifiers, Array: typedObj, Path: currPath}.Concrete()
-			if err != nil {
-				return nil, err
-			}
+	err = w.Walk(doc, func(interface{}) {}, func(loc walkLocation) error {
+		switch {
+		case loc.Found:
+			result, _ = w.Doc.Child(loc)
+		case loc.IsMap:
+			result = nil
+		default:
+			result = flavor.NewMatchingItem(loc.Token.(MatchingIndexToken))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
-			if isLast {
-				return typedObj[idx], nil
-			} else {
-				obj = typedObj[idx]
-			}
+	return result, nil
+}
 
-		case AfterLastIndexToken:
-			errMsg := "Expected not to find after last index token in path '%s' (not supported in find operations)"
-			return nil, fmt.Errorf(errMsg, op.Path)
+func (op FindOp) findAll(ptrs []Pointer, doc interface{}, m matchers) (interface{}, error) {
+	results := []interface{}{}
 
-		case MatchingIndexToken:
-			typedObj, ok := obj.([]interface{})
-			if !ok {
-				return nil, NewOpArrayMismatchTypeErr(currPath, obj)
-			}
-
-			var idxs []int
-
-			for itemIdx, item := range typedObj {
-				typedItem, ok := item.(map[interface{}]interface{})
-				if ok {
-					if typedItem[typedToken.Key] == typedToken.Value {
-						idxs = append(idxs, itemIdx)
-					}
-				}
-			}
-
-			if typedToken.Optional && len(idxs) == 0 {
-				// todo /blah=foo?:after, modifiers
-				obj = map[interface{}]interface{}{typedToken.Key: typedToken.Value}
-
-				if isLast {
-					return obj, nil
-				}
-			} else {
-				if len(idxs) != 1 {
-					return nil, OpMultipleMatchingIndexErr{currPath, idxs}
-				}
-
-				idx, err := ArrayIndex{Index: idxs[0], Modifiers: typedToken.Modifiers, Array: typedObj, Path: currPath}.Concrete()
-				if err != nil {
-					return nil, err
-				}
-
-				if isLast {
-					return typedObj[idx], nil
-				} else {
-					obj = typedObj[idx]
-				}
-			}
-
-		case KeyToken:
-			typedObj, ok := obj.(map[interface{}]interface{})
-			if !ok {
-				return nil, NewOpMapMismatchTypeErr(currPath, obj)
-			}
-
-			var found bool
-
-			obj, found = typedObj[typedToken.Key]
-			if !found && !typedToken.Optional {
-				return nil, OpMissingMapKeyErr{typedToken.Key, currPath, typedObj}
-			}
-
-			if isLast {
-				return typedObj[typedToken.Key], nil
-			} else {
-				if !found {
-					// Determine what type of value to create based on next token
-					switch tokens[i+2].(type) {
-					case MatchingIndexToken:
-						obj = []interface{}{}
-					case KeyToken:
-						obj = map[interface{}]interface{}{}
-					default:
-						errMsg := "Expected to find key or matching index token at path '%s'"
-						return nil, fmt.Errorf(errMsg, NewPointer(tokens[:i+3]))
-					}
-				}
-			}
+	for _, ptr := range ptrs {
+		result, err := FindOp{Path: ptr}.apply(doc, m)
+		if err != nil {
+			return nil, err
+		}
 
-		default:
-			return nil, OpUnexpectedTokenErr{token, currPath}
+		// Values found via nested wildcards are flattened into a single list
+		if _, nested := wildcardIndex(ptr); nested {
+			results = append(results, result.([]interface{})...)
+		} else {
+			results = append(results, result)
 		}
 	}
 
-	return doc, nil
+	return results, nil
+}
+
+// findArray returns array at given path; location is missing (without an error)
+// only if path is optional and array does not exist
+func findArray(path Pointer, doc interface{}, m matchers) (pointerLocation, []interface{}, error) {
+	loc, err := resolvePointer(path, doc, false, m)
+	if err != nil || loc.missing {
+		return loc, nil, err
+	}
+
+	obj, err := FindOp{Path: path}.apply(doc, m)
+	if err != nil {
+		return loc, nil, err
+	}
+
+	items, ok := obj.([]interface{})
+	if !ok {
+		return loc, nil, NewOpArrayMismatchTypeErr(path, obj)
+	}
+
+	return loc, items, nil
 }
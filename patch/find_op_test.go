@@ -361,7 +361,7 @@ var _ = Describe("FindOp.Apply", func() {
 			_, err := FindOp{Path: MustNewPointerFromString("/abc?/0")}.Apply(doc)
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(Equal(
-				"Expected to find key or matching index token at path '/abc?/0'"))
+				"Expected to find key, matching index or after last index token at path '/abc?/0'"))
 		})
 
 		It("returns an error if it's not a map when key is being accessed", func() {
@@ -376,4 +376,131 @@ var _ = Describe("FindOp.Apply", func() {
 				"Expected to find a map at path '/abc' but found '[]interface {}'"))
 		})
 	})
+
+	Describe("wildcard", func() {
+		doc := map[interface{}]interface{}{
+			"igs": []interface{}{
+				map[interface{}]interface{}{"name": "a", "jobs": []interface{}{"j1", "j2"}},
+				map[interface{}]interface{}{"name": "b", "jobs": []interface{}{"j3"}},
+			},
+			"empty": []interface{}{},
+		}
+
+		It("finds list of values within every array item or map value", func() {
+			res, err := FindOp{Path: MustNewPointerFromString("/igs/*/name")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{"a", "b"}))
+
+			res, err = FindOp{Path: MustNewPointerFromString("/igs/0/*")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{[]interface{}{"j1", "j2"}, "a"}))
+		})
+
+		It("flattens values found via nested wildcards", func() {
+			res, err := FindOp{Path: MustNewPointerFromString("/igs/*/jobs/*")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{"j1", "j2", "j3"}))
+		})
+
+		It("finds empty list for empty or optional missing collections", func() {
+			res, err := FindOp{Path: MustNewPointerFromString("/empty/*/name")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{}))
+
+			res, err = FindOp{Path: MustNewPointerFromString("/missing/*?")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{}))
+		})
+
+		It("finds nil for items missing optional values", func() {
+			res, err := FindOp{Path: MustNewPointerFromString("/igs/*?/disk")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{nil, nil}))
+		})
+
+		It("returns an error if value is missing within any item", func() {
+			_, err := FindOp{Path: MustNewPointerFromString("/igs/*/disk")}.Apply(doc)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected to find a map key 'disk' for path '/igs/0/disk' (found map keys: 'jobs', 'name')"))
+		})
+	})
+
+	Describe("all matching array items", func() {
+		It("finds list of values within every matching item", func() {
+			doc := []interface{}{
+				map[interface{}]interface{}{"name": "a", "v": 1},
+				map[interface{}]interface{}{"name": "b", "v": 2},
+				map[interface{}]interface{}{"name": "a", "v": 3},
+			}
+
+			res, err := FindOp{Path: MustNewPointerFromString("/name=a:all/v")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{1, 3}))
+		})
+	})
+
+	Describe("array item with multiple conditions or nested keys", func() {
+		doc := []interface{}{
+			map[interface{}]interface{}{"name": "a", "release": "r1", "v": 1},
+			map[interface{}]interface{}{"name": "a", "release": "r2", "v": 2, "nested": map[interface{}]interface{}{"k": "x"}},
+		}
+
+		It("finds array item that satisfies all conditions", func() {
+			res, err := FindOp{Path: MustNewPointerFromString("/name=a,release=r2/v")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(2))
+
+			res, err = FindOp{Path: MustNewPointerFromString("/nested.k=x/v")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(2))
+		})
+
+		It("returns an error if no items satisfy all conditions", func() {
+			_, err := FindOp{Path: MustNewPointerFromString("/name=a,release=r3")}.Apply(doc)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(
+				"Expected to find exactly one matching array item for path '/name=a,release=r3' but found 0"))
+		})
+	})
+
+	Describe("array item with typed values", func() {
+		It("finds array item with matching number value regardless of its Go type", func() {
+			doc := []interface{}{
+				map[interface{}]interface{}{"port": float64(8080), "v": 1},
+				map[interface{}]interface{}{"port": false, "v": 2},
+			}
+
+			res, err := FindOp{Path: MustNewPointerFromString("/port=8080/v")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(1))
+
+			res, err = FindOp{Path: MustNewPointerFromString("/port=false/v")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(2))
+		})
+	})
+
+	Describe("array item with regexp or glob conditions", func() {
+		doc := []interface{}{
+			map[interface{}]interface{}{"name": "worker-az1", "v": 1},
+			map[interface{}]interface{}{"name": "api-az1", "v": 2},
+		}
+
+		It("finds array item matching regexp or glob", func() {
+			res, err := FindOp{Path: MustNewPointerFromString("/name~=^api-/v")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(2))
+
+			res, err = FindOp{Path: MustNewPointerFromString("/name*=*-az1:all/v")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{1, 2}))
+		})
+
+		It("returns an error instead of finding missing item", func() {
+			_, err := FindOp{Path: MustNewPointerFromString("/name*=db-*:optional/v")}.Apply(doc)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(
+				"Expected to find matching array item for path '/name*=db-*:optional' (items cannot be created for regexp or glob conditions)"))
+		})
+	})
 })
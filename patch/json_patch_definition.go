@@ -0,0 +1,254 @@
+package patch
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// JSONPatchOpDefinition struct is useful for JSON and YAML unmarshaling
+// of https://tools.ietf.org/html/rfc6902 documents
+type JSONPatchOpDefinition struct {
+	Op    string       `json:"op" yaml:"op"`
+	Path  *string      `json:"path,omitempty" yaml:"path,omitempty"`
+	From  *string      `json:"from,omitempty" yaml:"from,omitempty"`
+	Value *interface{} `json:"value,omitempty" yaml:"value,omitempty"`
+}
+
+// UnmarshalJSON distinguishes explicit null value from missing value
+func (d *JSONPatchOpDefinition) UnmarshalJSON(data []byte) error {
+	type plainDef JSONPatchOpDefinition
+
+	var (
+		def    plainDef
+		fields map[string]json.RawMessage
+	)
+
+	err := json.Unmarshal(data, &def)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(data, &fields)
+	if err != nil {
+		return err
+	}
+
+	if _, found := fields["value"]; found && def.Value == nil {
+		var null interface{}
+		def.Value = &null
+	}
+
+	*d = JSONPatchOpDefinition(def)
+
+	return nil
+}
+
+// UnmarshalYAML distinguishes explicit null value from missing value
+func (d *JSONPatchOpDefinition) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plainDef JSONPatchOpDefinition
+
+	var (
+		def    plainDef
+		fields map[string]interface{}
+	)
+
+	err := unmarshal(&def)
+	if err != nil {
+		return err
+	}
+
+	err = unmarshal(&fields)
+	if err != nil {
+		return err
+	}
+
+	if _, found := fields["value"]; found && def.Value == nil {
+		var null interface{}
+		def.Value = &null
+	}
+
+	*d = JSONPatchOpDefinition(def)
+
+	return nil
+}
+
+type jsonPatchParser struct{}
+
+func NewOpsFromJSONPatch(opDefs []JSONPatchOpDefinition) (Ops, error) {
+	var ops []Op
+	var p jsonPatchParser
+
+	for i, opDef := range opDefs {
+		var op Op
+		var err error
+
+		opFmt := p.fmtOpDef(opDef)
+
+		switch opDef.Op {
+		case "add":
+			op, err = p.newAddOp(opDef)
+			if err != nil {
+				return nil, fmt.Errorf("Add operation [%d]: %s within\n%s", i, err, opFmt)
+			}
+
+		case "remove":
+			op, err = p.newRemoveOp(opDef)
+			if err != nil {
+				return nil, fmt.Errorf("Remove operation [%d]: %s within\n%s", i, err, opFmt)
+			}
+
+		case "replace":
+			op, err = p.newReplaceOp(opDef)
+			if err != nil {
+				return nil, fmt.Errorf("Replace operation [%d]: %s within\n%s", i, err, opFmt)
+			}
+
+		case "move":
+			op, err = p.newMoveOp(opDef)
+			if err != nil {
+				return nil, fmt.Errorf("Move operation [%d]: %s within\n%s", i, err, opFmt)
+			}
+
+		case "copy":
+			op, err = p.newCopyOp(opDef)
+			if err != nil {
+				return nil, fmt.Errorf("Copy operation [%d]: %s within\n%s", i, err, opFmt)
+			}
+
+		case "test":
+			op, err = p.newTestOp(opDef)
+			if err != nil {
+				return nil, fmt.Errorf("Test operation [%d]: %s within\n%s", i, err, opFmt)
+			}
+
+		default:
+			return nil, fmt.Errorf("Unknown operation [%d] with op '%s' within\n%s", i, opDef.Op, opFmt)
+		}
+
+		ops = append(ops, op)
+	}
+
+	return Ops(ops), nil
+}
+
+func (p jsonPatchParser) newAddOp(opDef JSONPatchOpDefinition) (JSONPatchAddOp, error) {
+	ptr, err := p.path(opDef)
+	if err != nil {
+		return JSONPatchAddOp{}, err
+	}
+
+	if opDef.Value == nil {
+		return JSONPatchAddOp{}, fmt.Errorf("Missing value")
+	}
+
+	return JSONPatchAddOp{Path: ptr, Value: *opDef.Value}, nil
+}
+
+func (p jsonPatchParser) newRemoveOp(opDef JSONPatchOpDefinition) (JSONPatchRemoveOp, error) {
+	ptr, err := p.path(opDef)
+	if err != nil {
+		return JSONPatchRemoveOp{}, err
+	}
+
+	return JSONPatchRemoveOp{Path: ptr}, nil
+}
+
+func (p jsonPatchParser) newReplaceOp(opDef JSONPatchOpDefinition) (JSONPatchReplaceOp, error) {
+	ptr, err := p.path(opDef)
+	if err != nil {
+		return JSONPatchReplaceOp{}, err
+	}
+
+	if opDef.Value == nil {
+		return JSONPatchReplaceOp{}, fmt.Errorf("Missing value")
+	}
+
+	return JSONPatchReplaceOp{Path: ptr, Value: *opDef.Value}, nil
+}
+
+func (p jsonPatchParser) newMoveOp(opDef JSONPatchOpDefinition) (JSONPatchMoveOp, error) {
+	pathPtr, err := p.path(opDef)
+	if err != nil {
+		return JSONPatchMoveOp{}, err
+	}
+
+	fromPtr, err := p.from(opDef)
+	if err != nil {
+		return JSONPatchMoveOp{}, err
+	}
+
+	return JSONPatchMoveOp{Path: pathPtr, From: fromPtr}, nil
+}
+
+func (p jsonPatchParser) newCopyOp(opDef JSONPatchOpDefinition) (JSONPatchCopyOp, error) {
+	pathPtr, err := p.path(opDef)
+	if err != nil {
+		return JSONPatchCopyOp{}, err
+	}
+
+	fromPtr, err := p.from(opDef)
+	if err != nil {
+		return JSONPatchCopyOp{}, err
+	}
+
+	return JSONPatchCopyOp{Path: pathPtr, From: fromPtr}, nil
+}
+
+func (p jsonPatchParser) newTestOp(opDef JSONPatchOpDefinition) (JSONPatchTestOp, error) {
+	ptr, err := p.path(opDef)
+	if err != nil {
+		return JSONPatchTestOp{}, err
+	}
+
+	if opDef.Value == nil {
+		return JSONPatchTestOp{}, fmt.Errorf("Missing value")
+	}
+
+	return JSONPatchTestOp{Path: ptr, Value: *opDef.Value}, nil
+}
+
+func (jsonPatchParser) path(opDef JSONPatchOpDefinition) (JSONPointer, error) {
+	if opDef.Path == nil {
+		return JSONPointer{}, fmt.Errorf("Missing path")
+	}
+
+	ptr, err := NewJSONPointerFromString(*opDef.Path)
+	if err != nil {
+		return JSONPointer{}, fmt.Errorf("Invalid path: %s", err)
+	}
+
+	return ptr, nil
+}
+
+func (jsonPatchParser) from(opDef JSONPatchOpDefinition) (JSONPointer, error) {
+	if opDef.From == nil {
+		return JSONPointer{}, fmt.Errorf("Missing from")
+	}
+
+	ptr, err := NewJSONPointerFromString(*opDef.From)
+	if err != nil {
+		return JSONPointer{}, fmt.Errorf("Invalid from: %s", err)
+	}
+
+	return ptr, nil
+}
+
+func (jsonPatchParser) fmtOpDef(opDef JSONPatchOpDefinition) string {
+	var (
+		redactedVal interface{} = "<redacted>"
+		htmlDecoder             = strings.NewReplacer("\\u003c", "<", "\\u003e", ">")
+	)
+
+	if opDef.Value != nil {
+		// can't JSON serialize generic interface{} anyway
+		opDef.Value = &redactedVal
+	}
+
+	bytes, err := json.MarshalIndent(opDef, "", "  ")
+	if err != nil {
+		return "<unknown>"
+	}
+
+	return htmlDecoder.Replace(string(bytes))
+}
@@ -0,0 +1,143 @@
+package patch_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v2"
+
+	. "github.com/SUSE/go-patch/patch"
+)
+
+var _ = Describe("NewOpsFromJSONPatch", func() {
+	var (
+		path                    = "/abc"
+		from                    = "/xyz"
+		invalidPath             = "abc"
+		val         interface{} = 123
+	)
+
+	It("supports 'add', 'remove', 'replace', 'move', 'copy', 'test' operations", func() {
+		opDefs := []JSONPatchOpDefinition{
+			{Op: "add", Path: &path, Value: &val},
+			{Op: "remove", Path: &path},
+			{Op: "replace", Path: &path, Value: &val},
+			{Op: "move", Path: &path, From: &from},
+			{Op: "copy", Path: &path, From: &from},
+			{Op: "test", Path: &path, Value: &val},
+		}
+
+		ops, err := NewOpsFromJSONPatch(opDefs)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(ops).To(Equal(Ops([]Op{
+			JSONPatchAddOp{Path: MustNewJSONPointerFromString("/abc"), Value: 123},
+			JSONPatchRemoveOp{Path: MustNewJSONPointerFromString("/abc")},
+			JSONPatchReplaceOp{Path: MustNewJSONPointerFromString("/abc"), Value: 123},
+			JSONPatchMoveOp{Path: MustNewJSONPointerFromString("/abc"), From: MustNewJSONPointerFromString("/xyz")},
+			JSONPatchCopyOp{Path: MustNewJSONPointerFromString("/abc"), From: MustNewJSONPointerFromString("/xyz")},
+			JSONPatchTestOp{Path: MustNewJSONPointerFromString("/abc"), Value: 123},
+		})))
+	})
+
+	It("returns error if operation is unknown", func() {
+		_, err := NewOpsFromJSONPatch([]JSONPatchOpDefinition{{Op: "qcopy", Path: &path}})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal(`Unknown operation [0] with op 'qcopy' within
+{
+  "op": "qcopy",
+  "path": "/abc"
+}`))
+	})
+
+	It("requires path", func() {
+		for _, op := range []string{"add", "remove", "replace", "move", "copy", "test"} {
+			_, err := NewOpsFromJSONPatch([]JSONPatchOpDefinition{{Op: op, Value: &val, From: &from}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("operation [0]: Missing path within"))
+		}
+	})
+
+	It("requires valid path", func() {
+		_, err := NewOpsFromJSONPatch([]JSONPatchOpDefinition{{Op: "add", Path: &invalidPath, Value: &val}})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal(`Add operation [0]: Invalid path: Expected to start with '/' within
+{
+  "op": "add",
+  "path": "abc",
+  "value": "<redacted>"
+}`))
+	})
+
+	It("requires value for 'add', 'replace' and 'test' operations", func() {
+		for _, op := range []string{"add", "replace", "test"} {
+			_, err := NewOpsFromJSONPatch([]JSONPatchOpDefinition{{Op: op, Path: &path}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("operation [0]: Missing value within"))
+		}
+	})
+
+	It("requires valid from for 'move' and 'copy' operations", func() {
+		_, err := NewOpsFromJSONPatch([]JSONPatchOpDefinition{{Op: "move", Path: &path}})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal(`Move operation [0]: Missing from within
+{
+  "op": "move",
+  "path": "/abc"
+}`))
+
+		_, err = NewOpsFromJSONPatch([]JSONPatchOpDefinition{{Op: "copy", Path: &path, From: &invalidPath}})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal(`Copy operation [0]: Invalid from: Expected to start with '/' within
+{
+  "op": "copy",
+  "path": "/abc",
+  "from": "abc"
+}`))
+	})
+})
+
+var _ = Describe("JSONPatchOpDefinition", func() {
+	It("deserializes explicit null value from JSON", func() {
+		var opDefs []JSONPatchOpDefinition
+
+		err := json.Unmarshal([]byte(`[{"op": "add", "path": "/a", "value": null}, {"op": "remove", "path": "/a"}]`), &opDefs)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(opDefs[0].Value).ToNot(BeNil())
+		Expect(*opDefs[0].Value).To(BeNil())
+		Expect(opDefs[1].Value).To(BeNil())
+
+		ops, err := NewOpsFromJSONPatch(opDefs[:1])
+		Expect(err).ToNot(HaveOccurred())
+
+		res, err := ops.Apply(map[interface{}]interface{}{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(map[interface{}]interface{}{"a": nil}))
+	})
+
+	It("deserializes explicit null value from YAML", func() {
+		var opDefs []JSONPatchOpDefinition
+
+		err := yaml.Unmarshal([]byte("- op: add\n  path: /a\n  value: ~\n- op: remove\n  path: /a\n"), &opDefs)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(opDefs[0].Op).To(Equal("add"))
+		Expect(*opDefs[0].Path).To(Equal("/a"))
+		Expect(opDefs[0].Value).ToNot(BeNil())
+		Expect(*opDefs[0].Value).To(BeNil())
+		Expect(opDefs[1].Value).To(BeNil())
+	})
+
+	It("serializes using RFC 6902 field names", func() {
+		var (
+			path             = "/abc"
+			null interface{} = nil
+		)
+
+		bytes, err := json.Marshal(JSONPatchOpDefinition{Op: "add", Path: &path, Value: &null})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(bytes)).To(Equal(`{"op":"add","path":"/abc","value":null}`))
+	})
+})
@@ -0,0 +1,298 @@
+package patch
+
+import (
+	"fmt"
+	"strconv"
+)
+
+type jsonPatchExporter struct{}
+
+// NewJSONPatchOpDefinitionsFromOps converts operations into https://tools.ietf.org/html/rfc6902
+// operations by resolving their paths against given document (which is not modified).
+// Resulting operations only contain concrete array indices and map keys,
+// hence they are only guaranteed to produce same result when applied to the same document.
+//
+// Test operations that check for absence are verified against the document
+// but omitted from the result since RFC 6902 is not able to express them.
+func NewJSONPatchOpDefinitionsFromOps(ops Ops, doc interface{}) ([]JSONPatchOpDefinition, error) {
+	var e jsonPatchExporter
+
+	doc, err := newDocMapFlavor(doc).cloneValue(doc)
+	if err != nil {
+		return nil, fmt.Errorf("Cloning document: %s", err)
+	}
+
+	opDefs := []JSONPatchOpDefinition{}
+
+	for i, op := range ops {
+		var newOpDefs []JSONPatchOpDefinition
+
+		newOpDefs, doc, err = e.export(op, doc)
+		if err != nil {
+			return nil, fmt.Errorf("Exporting operation [%d]: %s", i, err)
+		}
+
+		opDefs = append(opDefs, newOpDefs...)
+	}
+
+	return opDefs, nil
+}
+
+func (e jsonPatchExporter) export(op Op, doc interface{}) ([]JSONPatchOpDefinition, interface{}, error) {
+	if ops, found, err := expandWildcard(op, doc, nil); found {
+		if err != nil {
+			return nil, nil, err
+		}
+		return e.export(ops, doc)
+	}
+
+	switch typedOp := op.(type) {
+	case Ops:
+		var opDefs []JSONPatchOpDefinition
+
+		for _, op := range typedOp {
+			newOpDefs, newDoc, err := e.export(op, doc)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			opDefs = append(opDefs, newOpDefs...)
+			doc = newDoc
+		}
+
+		return opDefs, doc, nil
+
+	case DescriptiveOp:
+		opDefs, doc, err := e.export(typedOp.Op, doc)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Error '%s': %s", typedOp.ErrorMsg, err.Error())
+		}
+
+		return opDefs, doc, nil
+
+	case ReplaceOp:
+		return e.replace(typedOp, typedOp.Path, doc)
+
+	case AddOp:
+		return e.replace(typedOp, typedOp.Path.lastKeyOptional(), doc)
+
+	case DefaultOp:
+		return e.setDefault(typedOp, doc)
+
+	case MergeOp:
+		return e.replace(typedOp, typedOp.Path, doc)
+
+	case AppendUniqueOp:
+		replaceOp, found, err := typedOp.replaceOp(doc, nil)
+		if err != nil || !found {
+			return nil, doc, err
+		}
+
+		return e.replace(replaceOp, replaceOp.Path, doc)
+
+	case RemoveValueOp:
+		ops, err := typedOp.removeOps(doc, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return e.export(ops, doc)
+
+	case SortOp:
+		return e.replaceArray(typedOp, typedOp.Path, doc)
+
+	case DedupeOp:
+		ops, err := typedOp.removeOps(doc, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return e.export(ops, doc)
+
+	case ReorderOp:
+		arrayPath, _, found, err := typedOp.locate(doc, nil)
+		if err != nil || !found {
+			return nil, doc, err
+		}
+
+		return e.replaceArray(typedOp, arrayPath, doc)
+
+	case RemoveOp:
+		return e.remove(typedOp, doc)
+
+	case TestOp:
+		return e.test(typedOp, doc)
+
+	case QCopyOp:
+		value, err := FindOp{Path: typedOp.From}.Apply(doc)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return e.replace(ReplaceOp{Path: typedOp.Path, Value: value}, typedOp.Path, doc)
+
+	case QMoveOp:
+		ops, err := typedOp.ops(doc, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return e.export(ops, doc)
+
+	case JSONPatchAddOp:
+		return e.passThrough(op, doc, JSONPatchOpDefinition{Op: "add", Path: e.str(typedOp.Path), Value: &typedOp.Value})
+
+	case JSONPatchRemoveOp:
+		return e.passThrough(op, doc, JSONPatchOpDefinition{Op: "remove", Path: e.str(typedOp.Path)})
+
+	case JSONPatchReplaceOp:
+		return e.passThrough(op, doc, JSONPatchOpDefinition{Op: "replace", Path: e.str(typedOp.Path), Value: &typedOp.Value})
+
+	case JSONPatchMoveOp:
+		return e.passThrough(op, doc, JSONPatchOpDefinition{Op: "move", Path: e.str(typedOp.Path), From: e.str(typedOp.From)})
+
+	case JSONPatchCopyOp:
+		return e.passThrough(op, doc, JSONPatchOpDefinition{Op: "copy", Path: e.str(typedOp.Path), From: e.str(typedOp.From)})
+
+	case JSONPatchTestOp:
+		return e.passThrough(op, doc, JSONPatchOpDefinition{Op: "test", Path: e.str(typedOp.Path), Value: &typedOp.Value})
+
+	default:
+		return nil, nil, fmt.Errorf("Expected operation of type '%T' to be expressible as JSON patch", op)
+	}
+}
+
+// replace exports operations that set value at given path (e.g. ReplaceOp, MergeOp)
+func (e jsonPatchExporter) replace(op Op, path Pointer, doc interface{}) ([]JSONPatchOpDefinition, interface{}, error) {
+	loc, err := resolvePointer(path, doc, true, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	doc, err = op.Apply(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Capture value as it ended up in the document (including created parents)
+	value, err := FindOp{Path: NewPointer(loc.tokens)}.Apply(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	value, err = newDocMapFlavor(doc).cloneValue(value)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Cloning value: %s", err)
+	}
+
+	opDef := JSONPatchOpDefinition{Op: "replace", Path: e.str(loc.jsonPointer()), Value: &value}
+
+	if loc.missing {
+		opDef.Op = "add"
+	}
+
+	return []JSONPatchOpDefinition{opDef}, doc, nil
+}
+
+func (e jsonPatchExporter) setDefault(op DefaultOp, doc interface{}) ([]JSONPatchOpDefinition, interface{}, error) {
+	path := op.Path.lastKeyOptional()
+
+	loc, err := resolvePointer(path, doc, true, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !loc.missing {
+		// Existing value is left untouched
+		return nil, doc, nil
+	}
+
+	return e.replace(op, path, doc)
+}
+
+// replaceArray exports operations that rearrange items of an array at given path
+// as a replacement of the whole array unless optional array is missing
+func (e jsonPatchExporter) replaceArray(op Op, path Pointer, doc interface{}) ([]JSONPatchOpDefinition, interface{}, error) {
+	loc, _, err := findArray(path, doc, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if loc.missing {
+		return nil, doc, nil
+	}
+
+	return e.replace(op, path, doc)
+}
+
+func (e jsonPatchExporter) remove(op RemoveOp, doc interface{}) ([]JSONPatchOpDefinition, interface{}, error) {
+	loc, err := resolvePointer(op.Path, doc, false, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	doc, err = op.Apply(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if loc.missing {
+		// Optional location did not exist, hence nothing was removed
+		return nil, doc, nil
+	}
+
+	return []JSONPatchOpDefinition{{Op: "remove", Path: e.str(loc.jsonPointer())}}, doc, nil
+}
+
+func (e jsonPatchExporter) test(op TestOp, doc interface{}) ([]JSONPatchOpDefinition, interface{}, error) {
+	doc, err := op.Apply(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if op.Absent {
+		return nil, doc, nil
+	}
+
+	loc, err := resolvePointer(op.Path, doc, false, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if loc.missing {
+		return nil, nil, fmt.Errorf("Expected to find '%s' to express test operation as JSON patch", op.Path)
+	}
+
+	value := op.Value
+
+	return []JSONPatchOpDefinition{{Op: "test", Path: e.str(loc.jsonPointer()), Value: &value}}, doc, nil
+}
+
+func (jsonPatchExporter) passThrough(op Op, doc interface{}, opDef JSONPatchOpDefinition) ([]JSONPatchOpDefinition, interface{}, error) {
+	doc, err := op.Apply(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return []JSONPatchOpDefinition{opDef}, doc, nil
+}
+
+func (jsonPatchExporter) str(ptr JSONPointer) *string {
+	str := ptr.String()
+	return &str
+}
+
+func (l pointerLocation) jsonPointer() JSONPointer {
+	var strs []string
+
+	for _, token := range l.tokens[1:] {
+		switch typedToken := token.(type) {
+		case IndexToken:
+			strs = append(strs, strconv.Itoa(typedToken.Index))
+		case KeyToken:
+			strs = append(strs, typedToken.Key)
+		}
+	}
+
+	return JSONPointer{strs}
+}
@@ -0,0 +1,340 @@
+package patch_test
+
+import (
+	"encoding/json"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v2"
+
+	. "github.com/SUSE/go-patch/patch"
+)
+
+var _ = Describe("NewJSONPatchOpDefinitionsFromOps", func() {
+	var doc interface{}
+
+	// Operations modify documents in place hence fresh copy is used for each application
+	newDoc := func() interface{} {
+		var doc interface{}
+
+		err := yaml.Unmarshal([]byte(`
+key: 1
+map:
+  nested: 2
+array: [3, 4, 5]
+items:
+- name: item6
+- name: item7
+  count: 8
+`), &doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		return doc
+	}
+
+	BeforeEach(func() {
+		doc = newDoc()
+	})
+
+	export := func(opsStr string) string {
+		var opDefs []OpDefinition
+
+		err := yaml.Unmarshal([]byte(opsStr), &opDefs)
+		Expect(err).ToNot(HaveOccurred())
+
+		ops, err := NewOpsFromDefinitions(opDefs)
+		Expect(err).ToNot(HaveOccurred())
+
+		jsonOpDefs, err := NewJSONPatchOpDefinitionsFromOps(ops, doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		// Exported operations must produce the same result as original operations
+		jsonOps, err := NewOpsFromJSONPatch(jsonOpDefs)
+		Expect(err).ToNot(HaveOccurred())
+
+		jsonRes, err := jsonOps.Apply(newDoc())
+		Expect(err).ToNot(HaveOccurred())
+
+		res, err := ops.Apply(newDoc())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(jsonRes).To(Equal(res))
+
+		bytes, err := json.Marshal(jsonOpDefs)
+		Expect(err).ToNot(HaveOccurred())
+
+		return string(bytes)
+	}
+
+	It("converts replacement of existing values to 'replace'", func() {
+		Expect(export(`
+- type: replace
+  path: /key
+  value: 10
+- type: replace
+  path: /array/-1
+  value: 10
+- type: replace
+  path: /items/name=item7/count
+  value: 10
+`)).To(MatchJSON(`[
+			{"op": "replace", "path": "/key", "value": 10},
+			{"op": "replace", "path": "/array/2", "value": 10},
+			{"op": "replace", "path": "/items/1/count", "value": 10}
+		]`))
+	})
+
+	It("converts creation of missing values to 'add' of the first missing location", func() {
+		Expect(export(`
+- type: replace
+  path: /new?/nested/key
+  value: 10
+- type: replace
+  path: /items/name=item9?/count
+  value: 10
+- type: replace
+  path: /array/-
+  value: 10
+- type: replace
+  path: /array/0:before
+  value: 10
+- type: replace
+  path: /items/name=item6:after
+  value: {name: item10}
+`)).To(MatchJSON(`[
+			{"op": "add", "path": "/new", "value": {"nested": {"key": 10}}},
+			{"op": "add", "path": "/items/2", "value": {"name": "item9", "count": 10}},
+			{"op": "add", "path": "/array/3", "value": 10},
+			{"op": "add", "path": "/array/0", "value": 10},
+			{"op": "add", "path": "/items/1", "value": {"name": "item10"}}
+		]`))
+	})
+
+	It("converts removal to 'remove' of concrete location", func() {
+		Expect(export(`
+- type: remove
+  path: /items/name=item7/count
+- type: remove
+  path: /array/1:prev
+- type: remove
+  path: /not-there?
+`)).To(MatchJSON(`[
+			{"op": "remove", "path": "/items/1/count"},
+			{"op": "remove", "path": "/array/0"}
+		]`))
+	})
+
+	It("converts operations with wildcards into operations for every matched location", func() {
+		Expect(export(`
+- type: replace
+  path: /items/*/count?
+  value: 10
+- type: remove
+  path: /array/*
+`)).To(MatchJSON(`[
+			{"op": "replace", "path": "/items/1/count", "value": 10},
+			{"op": "add", "path": "/items/0/count", "value": 10},
+			{"op": "remove", "path": "/array/2"},
+			{"op": "remove", "path": "/array/1"},
+			{"op": "remove", "path": "/array/0"}
+		]`))
+	})
+
+	It("converts value tests and omits absence tests", func() {
+		Expect(export(`
+- type: test
+  path: /items/name=item7/count
+  value: 8
+- type: test
+  path: /not-there
+  absent: true
+`)).To(MatchJSON(`[
+			{"op": "test", "path": "/items/1/count", "value": 8}
+		]`))
+	})
+
+	It("converts copy and move operations into values", func() {
+		Expect(export(`
+- type: qcopy
+  from: /map
+  path: /map2?
+- type: qmove
+  from: /key
+  path: /array/-
+`)).To(MatchJSON(`[
+			{"op": "add", "path": "/map2", "value": {"nested": 2}},
+			{"op": "add", "path": "/array/3", "value": 1},
+			{"op": "remove", "path": "/key"}
+		]`))
+	})
+
+	It("converts merge operations into values", func() {
+		Expect(export(`
+- type: merge
+  path: /map
+  value: {nested: null, other: 10}
+- type: merge
+  path: /new?/map
+  value: {other: 10}
+`)).To(MatchJSON(`[
+			{"op": "replace", "path": "/map", "value": {"other": 10}},
+			{"op": "add", "path": "/new", "value": {"map": {"other": 10}}}
+		]`))
+	})
+
+	It("converts add and default operations into 'add' of missing locations", func() {
+		Expect(export(`
+- type: add
+  path: /map/other
+  value: 10
+- type: default
+  path: /map/nested
+  value: 10
+- type: default
+  path: /items/name=item6?/count
+  value: 10
+- type: default
+  path: /items/name=item7?/count
+  value: 10
+`)).To(MatchJSON(`[
+			{"op": "add", "path": "/map/other", "value": 10},
+			{"op": "add", "path": "/items/0/count", "value": 10}
+		]`))
+	})
+
+	It("converts append-unique and remove-value operations for affected items only", func() {
+		Expect(export(`
+- type: append-unique
+  path: /array
+  value: 4
+- type: append-unique
+  path: /array
+  value: 6
+- type: append-unique
+  path: /tags?
+  value: a
+- type: remove-value
+  path: /items
+  value: {name: item6}
+`)).To(MatchJSON(`[
+			{"op": "add", "path": "/array/3", "value": 6},
+			{"op": "add", "path": "/tags", "value": ["a"]},
+			{"op": "remove", "path": "/items/0"}
+		]`))
+	})
+
+	It("converts sort, dedupe and reorder operations", func() {
+		Expect(export(`
+- type: sort
+  path: /items
+  key: name
+- type: reorder
+  path: /items/name=item7
+  index: 0
+- type: dedupe
+  path: /array
+- type: sort
+  path: /missing?
+`)).To(MatchJSON(`[
+			{"op": "replace", "path": "/items", "value": [{"name": "item6"}, {"name": "item7", "count": 8}]},
+			{"op": "replace", "path": "/items", "value": [{"name": "item7", "count": 8}, {"name": "item6"}]}
+		]`))
+	})
+
+	It("resolves each operation against the result of previous operations", func() {
+		Expect(export(`
+- type: remove
+  path: /items/0
+- type: replace
+  path: /items/name=item7/count
+  value: 10
+`)).To(MatchJSON(`[
+			{"op": "remove", "path": "/items/0"},
+			{"op": "replace", "path": "/items/0/count", "value": 10}
+		]`))
+	})
+
+	It("escapes keys according to RFC 6901", func() {
+		Expect(export(`
+- type: replace
+  path: /a~1b~7c?
+  value: 10
+`)).To(MatchJSON(`[{"op": "add", "path": "/a~1b:c", "value": 10}]`))
+	})
+
+	It("passes through JSON patch operations", func() {
+		ops := Ops{
+			JSONPatchAddOp{Path: MustNewJSONPointerFromString("/array/0"), Value: 10},
+			JSONPatchMoveOp{From: MustNewJSONPointerFromString("/key"), Path: MustNewJSONPointerFromString("/key2")},
+		}
+
+		opDefs, err := NewJSONPatchOpDefinitionsFromOps(ops, doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		bytes, err := json.Marshal(opDefs)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(bytes)).To(MatchJSON(`[
+			{"op": "add", "path": "/array/0", "value": 10},
+			{"op": "move", "from": "/key", "path": "/key2"}
+		]`))
+	})
+
+	It("converts diff between two documents", func() {
+		var right interface{}
+
+		err := yaml.Unmarshal([]byte(`
+key: 1
+map:
+  nested: 3
+  new: 4
+array: [3, 4]
+items:
+- name: item6
+- name: item7
+  count: 8
+`), &right)
+		Expect(err).ToNot(HaveOccurred())
+
+		opDefs, err := NewJSONPatchOpDefinitionsFromOps(Diff{Left: doc, Right: right}.Calculate(), doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		ops, err := NewOpsFromJSONPatch(opDefs)
+		Expect(err).ToNot(HaveOccurred())
+
+		res, err := ops.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(right))
+	})
+
+	It("does not modify given document", func() {
+		_, err := NewJSONPatchOpDefinitionsFromOps(Ops{
+			ReplaceOp{Path: MustNewPointerFromString("/map/nested"), Value: 10},
+		}, doc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(doc.(map[interface{}]interface{})["map"]).To(Equal(map[interface{}]interface{}{"nested": 2}))
+	})
+
+	It("returns an error if operation fails", func() {
+		ops := Ops{
+			RemoveOp{Path: MustNewPointerFromString("/key")},
+			DescriptiveOp{Op: RemoveOp{Path: MustNewPointerFromString("/key")}, ErrorMsg: "custom"},
+		}
+
+		_, err := NewJSONPatchOpDefinitionsFromOps(ops, doc)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Exporting operation [1]: Error 'custom': " +
+			"Expected to find a map key 'key' for path '/key' (found map keys: 'array', 'items', 'map')"))
+	})
+
+	It("returns an error if operation cannot be expressed", func() {
+		_, err := NewJSONPatchOpDefinitionsFromOps(Ops{ErrOp{errors.New("fake-err")}}, doc)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal(
+			"Exporting operation [0]: Expected operation of type 'patch.ErrOp' to be expressible as JSON patch"))
+
+		_, err = NewJSONPatchOpDefinitionsFromOps(Ops{FindOp{Path: MustNewPointerFromString("/key")}}, doc)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal(
+			"Exporting operation [0]: Expected operation of type 'patch.FindOp' to be expressible as JSON patch"))
+	})
+})
@@ -0,0 +1,170 @@
+package patch
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Following operations implement https://tools.ietf.org/html/rfc6902 semantics
+// by resolving JSON pointers against the document and delegating to basic operations.
+
+type JSONPatchAddOp struct {
+	Path  JSONPointer
+	Value interface{}
+}
+
+type JSONPatchRemoveOp struct {
+	Path JSONPointer
+}
+
+type JSONPatchReplaceOp struct {
+	Path  JSONPointer
+	Value interface{}
+}
+
+type JSONPatchMoveOp struct {
+	Path JSONPointer
+	From JSONPointer
+}
+
+type JSONPatchCopyOp struct {
+	Path JSONPointer
+	From JSONPointer
+}
+
+type JSONPatchTestOp struct {
+	Path  JSONPointer
+	Value interface{}
+}
+
+var _ Op = JSONPatchAddOp{}
+var _ Op = JSONPatchRemoveOp{}
+var _ Op = JSONPatchReplaceOp{}
+var _ Op = JSONPatchMoveOp{}
+var _ Op = JSONPatchCopyOp{}
+var _ Op = JSONPatchTestOp{}
+
+func (op JSONPatchAddOp) Apply(doc interface{}) (interface{}, error) {
+	ptr, err := op.Path.Pointer(doc, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return ReplaceOp{Path: ptr, Value: op.Value}.Apply(doc)
+}
+
+func (op JSONPatchRemoveOp) Apply(doc interface{}) (interface{}, error) {
+	ptr, err := op.Path.Pointer(doc, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return RemoveOp{Path: ptr}.Apply(doc)
+}
+
+func (op JSONPatchReplaceOp) Apply(doc interface{}) (interface{}, error) {
+	ptr, err := op.Path.Pointer(doc, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return ReplaceOp{Path: ptr, Value: op.Value}.Apply(doc)
+}
+
+func (op JSONPatchMoveOp) Apply(doc interface{}) (interface{}, error) {
+	ops, err := op.ops(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return ops.apply(doc, nil)
+}
+
+// ops returns operations that remove found value and then add it at path
+func (op JSONPatchMoveOp) ops(doc interface{}) (Ops, error) {
+	if op.From.IsProperPrefixOf(op.Path) {
+		return nil, fmt.Errorf("Expected to not move '%s' into one of its children '%s'", op.From, op.Path)
+	}
+
+	fromPtr, err := op.From.Pointer(doc, false)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := FindOp{Path: fromPtr}.Apply(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	if op.From.String() == op.Path.String() {
+		return Ops{}, nil
+	}
+
+	return Ops{RemoveOp{Path: fromPtr}, JSONPatchAddOp{Path: op.Path, Value: value}}, nil
+}
+
+func (op JSONPatchCopyOp) Apply(doc interface{}) (interface{}, error) {
+	fromPtr, err := op.From.Pointer(doc, false)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := FindOp{Path: fromPtr}.Apply(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return JSONPatchAddOp{Path: op.Path, Value: value}.Apply(doc)
+}
+
+func (op JSONPatchTestOp) Apply(doc interface{}) (interface{}, error) {
+	ptr, err := op.Path.Pointer(doc, false)
+	if err != nil {
+		return nil, err
+	}
+
+	foundVal, err := FindOp{Path: ptr}.Apply(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	// Numbers are compared by their value regardless of Go type
+	// since JSON decoders and YAML decoders pick different types (e.g. float64 vs int)
+	if !reflect.DeepEqual(jsonPatchNormalize(foundVal), jsonPatchNormalize(op.Value)) {
+		return nil, fmt.Errorf("Found value does not match expected value")
+	}
+
+	// Return same input document
+	return doc, nil
+}
+
+func jsonPatchNormalize(in interface{}) interface{} {
+	switch typedIn := in.(type) {
+	case map[interface{}]interface{}:
+		out := map[string]interface{}{}
+		for k, v := range typedIn {
+			out[fmt.Sprintf("%v", k)] = jsonPatchNormalize(v)
+		}
+		return out
+
+	case map[string]interface{}:
+		out := map[string]interface{}{}
+		for k, v := range typedIn {
+			out[k] = jsonPatchNormalize(v)
+		}
+		return out
+
+	case []interface{}:
+		out := make([]interface{}, len(typedIn))
+		for i, v := range typedIn {
+			out[i] = jsonPatchNormalize(v)
+		}
+		return out
+
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32:
+		return reflect.ValueOf(in).Convert(reflect.TypeOf(float64(0))).Float()
+
+	default:
+		return in
+	}
+}
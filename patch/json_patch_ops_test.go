@@ -0,0 +1,267 @@
+package patch_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v2"
+
+	. "github.com/SUSE/go-patch/patch"
+)
+
+var _ = Describe("JSON patch operations", func() {
+	// Examples from https://tools.ietf.org/html/rfc6902#appendix-A
+	type Example struct {
+		Doc    string
+		Patch  string
+		Result string
+		Err    string
+	}
+
+	examples := map[string]Example{
+		"A.1. Adding an Object Member": {
+			Doc:    `{ "foo": "bar"}`,
+			Patch:  `[{ "op": "add", "path": "/baz", "value": "qux" }]`,
+			Result: `{ "baz": "qux", "foo": "bar" }`,
+		},
+		"A.2. Adding an Array Element": {
+			Doc:    `{ "foo": [ "bar", "baz" ] }`,
+			Patch:  `[{ "op": "add", "path": "/foo/1", "value": "qux" }]`,
+			Result: `{ "foo": [ "bar", "qux", "baz" ] }`,
+		},
+		"A.3. Removing an Object Member": {
+			Doc:    `{ "baz": "qux", "foo": "bar" }`,
+			Patch:  `[{ "op": "remove", "path": "/baz" }]`,
+			Result: `{ "foo": "bar" }`,
+		},
+		"A.4. Removing an Array Element": {
+			Doc:    `{ "foo": [ "bar", "qux", "baz" ] }`,
+			Patch:  `[{ "op": "remove", "path": "/foo/1" }]`,
+			Result: `{ "foo": [ "bar", "baz" ] }`,
+		},
+		"A.5. Replacing a Value": {
+			Doc:    `{ "baz": "qux", "foo": "bar" }`,
+			Patch:  `[{ "op": "replace", "path": "/baz", "value": "boo" }]`,
+			Result: `{ "baz": "boo", "foo": "bar" }`,
+		},
+		"A.6. Moving a Value": {
+			Doc:    `{ "foo": { "bar": "baz", "waldo": "fred" }, "qux": { "corge": "grault" } }`,
+			Patch:  `[{ "op": "move", "from": "/foo/waldo", "path": "/qux/thud" }]`,
+			Result: `{ "foo": { "bar": "baz" }, "qux": { "corge": "grault", "thud": "fred" } }`,
+		},
+		"A.7. Moving an Array Element": {
+			Doc:    `{ "foo": [ "all", "grass", "cows", "eat" ] }`,
+			Patch:  `[{ "op": "move", "from": "/foo/1", "path": "/foo/3" }]`,
+			Result: `{ "foo": [ "all", "cows", "eat", "grass" ] }`,
+		},
+		"A.8. Testing a Value: Success": {
+			Doc:    `{ "baz": "qux", "foo": [ "a", 2, "c" ] }`,
+			Patch:  `[{ "op": "test", "path": "/baz", "value": "qux" }, { "op": "test", "path": "/foo/1", "value": 2 }]`,
+			Result: `{ "baz": "qux", "foo": [ "a", 2, "c" ] }`,
+		},
+		"A.9. Testing a Value: Error": {
+			Doc:   `{ "baz": "qux" }`,
+			Patch: `[{ "op": "test", "path": "/baz", "value": "bar" }]`,
+			Err:   "Found value does not match expected value",
+		},
+		"A.10. Adding a Nested Member Object": {
+			Doc:    `{ "foo": "bar" }`,
+			Patch:  `[{ "op": "add", "path": "/child", "value": { "grandchild": { } } }]`,
+			Result: `{ "foo": "bar", "child": { "grandchild": { } } }`,
+		},
+		"A.11. Ignoring Unrecognized Elements": {
+			Doc:    `{ "foo": "bar" }`,
+			Patch:  `[{ "op": "add", "path": "/baz", "value": "qux", "xyz": 123 }]`,
+			Result: `{ "foo": "bar", "baz": "qux" }`,
+		},
+		"A.12. Adding to a Nonexistent Target": {
+			Doc:   `{ "foo": "bar" }`,
+			Patch: `[{ "op": "add", "path": "/baz/bat", "value": "qux" }]`,
+			Err:   "Expected to find a map key 'baz' for path '/baz' (found map keys: 'foo')",
+		},
+		"A.14. ~ Escape Ordering": {
+			Doc:    `{ "/": 9, "~1": 10 }`,
+			Patch:  `[{"op": "test", "path": "/~01", "value": 10}]`,
+			Result: `{ "/": 9, "~1": 10 }`,
+		},
+		"A.15. Comparing Strings and Numbers": {
+			Doc:   `{ "/": 9, "~1": 10 }`,
+			Patch: `[{"op": "test", "path": "/~01", "value": "10"}]`,
+			Err:   "Found value does not match expected value",
+		},
+		"A.16. Adding an Array Value": {
+			Doc:    `{ "foo": ["bar"] }`,
+			Patch:  `[{ "op": "add", "path": "/foo/-", "value": ["abc", "def"] }]`,
+			Result: `{ "foo": ["bar", ["abc", "def"]] }`,
+		},
+	}
+
+	for name, example := range examples {
+		example := example
+
+		It("satisfies RFC 6902 example "+name, func() {
+			var doc interface{}
+
+			err := yaml.Unmarshal([]byte(example.Doc), &doc)
+			Expect(err).ToNot(HaveOccurred())
+
+			var opDefs []JSONPatchOpDefinition
+
+			err = json.Unmarshal([]byte(example.Patch), &opDefs)
+			Expect(err).ToNot(HaveOccurred())
+
+			ops, err := NewOpsFromJSONPatch(opDefs)
+			Expect(err).ToNot(HaveOccurred())
+
+			res, err := ops.Apply(doc)
+			if len(example.Err) > 0 {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal(example.Err))
+				return
+			}
+
+			Expect(err).ToNot(HaveOccurred())
+
+			var expectedRes interface{}
+
+			err = yaml.Unmarshal([]byte(example.Result), &expectedRes)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(res).To(Equal(expectedRes))
+		})
+	}
+
+	Describe("JSONPatchAddOp", func() {
+		It("replaces existing map key", func() {
+			res, err := JSONPatchAddOp{
+				Path:  MustNewJSONPointerFromString("/a"),
+				Value: 2,
+			}.Apply(map[interface{}]interface{}{"a": 1})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{"a": 2}))
+		})
+
+		It("replaces entire document", func() {
+			res, err := JSONPatchAddOp{Path: MustNewJSONPointerFromString(""), Value: 2}.Apply(1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(2))
+		})
+
+		It("appends to an array when index equals array length", func() {
+			res, err := JSONPatchAddOp{
+				Path:  MustNewJSONPointerFromString("/2"),
+				Value: 3,
+			}.Apply([]interface{}{1, 2})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{1, 2, 3}))
+		})
+
+		It("returns an error if index is out of bounds", func() {
+			_, err := JSONPatchAddOp{
+				Path:  MustNewJSONPointerFromString("/3"),
+				Value: 3,
+			}.Apply([]interface{}{1, 2})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(
+				"Expected to find array index '3' but found array of length '2' for path '/3'"))
+		})
+	})
+
+	Describe("JSONPatchRemoveOp", func() {
+		It("returns an error if location does not exist", func() {
+			_, err := JSONPatchRemoveOp{
+				Path: MustNewJSONPointerFromString("/b"),
+			}.Apply(map[interface{}]interface{}{"a": 1})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected to find a map key 'b' for path '/b' (found map keys: 'a')"))
+		})
+	})
+
+	Describe("JSONPatchReplaceOp", func() {
+		It("replaces array item without inserting", func() {
+			res, err := JSONPatchReplaceOp{
+				Path:  MustNewJSONPointerFromString("/0"),
+				Value: 3,
+			}.Apply([]interface{}{1, 2})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{3, 2}))
+		})
+
+		It("returns an error if location does not exist", func() {
+			_, err := JSONPatchReplaceOp{
+				Path:  MustNewJSONPointerFromString("/b"),
+				Value: 2,
+			}.Apply(map[interface{}]interface{}{"a": 1})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected to find a map key 'b' for path '/b' (found map keys: 'a')"))
+		})
+	})
+
+	Describe("JSONPatchMoveOp", func() {
+		It("does nothing when moving to the same location", func() {
+			res, err := JSONPatchMoveOp{
+				From: MustNewJSONPointerFromString("/a"),
+				Path: MustNewJSONPointerFromString("/a"),
+			}.Apply(map[interface{}]interface{}{"a": 1})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{"a": 1}))
+		})
+
+		It("returns an error if moving into its own child", func() {
+			_, err := JSONPatchMoveOp{
+				From: MustNewJSONPointerFromString("/a"),
+				Path: MustNewJSONPointerFromString("/a/b"),
+			}.Apply(map[interface{}]interface{}{"a": map[interface{}]interface{}{}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected to not move '/a' into one of its children '/a/b'"))
+		})
+
+		It("returns an error if from location does not exist", func() {
+			_, err := JSONPatchMoveOp{
+				From: MustNewJSONPointerFromString("/b"),
+				Path: MustNewJSONPointerFromString("/c"),
+			}.Apply(map[interface{}]interface{}{"a": 1})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected to find a map key 'b' for path '/b' (found map keys: 'a')"))
+		})
+	})
+
+	Describe("JSONPatchCopyOp", func() {
+		It("copies value into an array", func() {
+			doc := map[interface{}]interface{}{
+				"a": map[interface{}]interface{}{"b": 1},
+				"c": []interface{}{1},
+			}
+
+			res, err := JSONPatchCopyOp{
+				From: MustNewJSONPointerFromString("/a"),
+				Path: MustNewJSONPointerFromString("/c/0"),
+			}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{
+				"a": map[interface{}]interface{}{"b": 1},
+				"c": []interface{}{map[interface{}]interface{}{"b": 1}, 1},
+			}))
+		})
+	})
+
+	Describe("JSONPatchTestOp", func() {
+		It("compares numbers regardless of their type", func() {
+			_, err := JSONPatchTestOp{
+				Path:  MustNewJSONPointerFromString("/a"),
+				Value: map[string]interface{}{"b": float64(1)},
+			}.Apply(map[interface{}]interface{}{"a": map[interface{}]interface{}{"b": 1}})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("returns an error if location does not exist", func() {
+			_, err := JSONPatchTestOp{
+				Path:  MustNewJSONPointerFromString("/b"),
+				Value: 1,
+			}.Apply(map[interface{}]interface{}{"a": 1})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected to find a map key 'b' for path '/b' (found map keys: 'a')"))
+		})
+	})
+})
@@ -0,0 +1,162 @@
+package patch
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	jsonPointerDecoder = strings.NewReplacer("~1", "/", "~0", "~")
+	jsonPointerEncoder = strings.NewReplacer("~", "~0", "/", "~1")
+)
+
+// JSONPointer is a strict https://tools.ietf.org/html/rfc6901 pointer.
+// Unlike Pointer, its tokens do not carry any type information
+// (same token may refer to a map key or an array index),
+// hence they are interpreted against a particular document.
+type JSONPointer struct {
+	tokens []string
+}
+
+func MustNewJSONPointerFromString(str string) JSONPointer {
+	ptr, err := NewJSONPointerFromString(str)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return ptr
+}
+
+func NewJSONPointerFromString(str string) (JSONPointer, error) {
+	if len(str) == 0 {
+		return JSONPointer{}, nil
+	}
+
+	if !strings.HasPrefix(str, "/") {
+		return JSONPointer{}, fmt.Errorf("Expected to start with '/'")
+	}
+
+	tokens := strings.Split(str, "/")[1:]
+
+	for i, tok := range tokens {
+		for j := 0; j < len(tok); j++ {
+			if tok[j] == '~' && (j+1 == len(tok) || (tok[j+1] != '0' && tok[j+1] != '1')) {
+				return JSONPointer{}, fmt.Errorf("Expected '~' to be followed by '0' or '1' in token '%s'", tok)
+			}
+		}
+
+		tokens[i] = jsonPointerDecoder.Replace(tok)
+	}
+
+	return JSONPointer{tokens}, nil
+}
+
+func (p JSONPointer) Tokens() []string { return p.tokens }
+
+func (p JSONPointer) String() string {
+	var str string
+
+	for _, tok := range p.tokens {
+		str += "/" + jsonPointerEncoder.Replace(tok)
+	}
+
+	return str
+}
+
+// IsProperPrefixOf returns true if other pointer points to a location within this pointer
+func (p JSONPointer) IsProperPrefixOf(other JSONPointer) bool {
+	if len(p.tokens) >= len(other.tokens) {
+		return false
+	}
+
+	for i, tok := range p.tokens {
+		if other.tokens[i] != tok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Pointer converts JSON pointer into a Pointer based on the contents of the document.
+// If insertion is true, last token is allowed to refer to a non-existent map key
+// or an array position (including '-') to insert at.
+func (p JSONPointer) Pointer(doc interface{}, insertion bool) (Pointer, error) {
+	tokens := []Token{RootToken{}}
+	obj := doc
+
+	for i, tok := range p.tokens {
+		isLast := i == len(p.tokens)-1
+
+		switch typedObj := obj.(type) {
+		case []interface{}:
+			currPath := NewPointer(append(append([]Token{}, tokens...), KeyToken{Key: tok}))
+
+			if tok == "-" && isLast && insertion {
+				tokens = append(tokens, AfterLastIndexToken{})
+				continue
+			}
+
+			idx, err := p.arrayIndex(tok)
+			if err != nil {
+				return Pointer{}, fmt.Errorf("Expected to find array index at path '%s' but found '%s'", currPath, tok)
+			}
+
+			currPath = NewPointer(append(append([]Token{}, tokens...), IndexToken{Index: idx}))
+
+			if isLast && insertion {
+				switch {
+				case idx == len(typedObj):
+					tokens = append(tokens, AfterLastIndexToken{})
+				case idx < len(typedObj):
+					tokens = append(tokens, IndexToken{Index: idx, Modifiers: []Modifier{BeforeModifier{}}})
+				default:
+					return Pointer{}, OpMissingIndexErr{idx, typedObj, currPath}
+				}
+				continue
+			}
+
+			if idx >= len(typedObj) {
+				return Pointer{}, OpMissingIndexErr{idx, typedObj, currPath}
+			}
+
+			tokens = append(tokens, IndexToken{Index: idx})
+			obj = typedObj[idx]
+
+		default:
+			currPath := NewPointer(append(append([]Token{}, tokens...), KeyToken{Key: tok}))
+
+			mapObj, ok := newDocMap(obj)
+			if !ok {
+				return Pointer{}, OpMismatchTypeErr{"a map or an array", currPath, obj}
+			}
+
+			var found bool
+
+			obj, found = mapObj.Get(tok)
+
+			if isLast && insertion {
+				tokens = append(tokens, KeyToken{Key: tok, Optional: !found})
+				continue
+			}
+
+			if !found {
+				return Pointer{}, OpMissingMapKeyErr{tok, currPath, mapObj.IfaceMap()}
+			}
+
+			tokens = append(tokens, KeyToken{Key: tok})
+		}
+	}
+
+	return NewPointer(tokens), nil
+}
+
+func (JSONPointer) arrayIndex(tok string) (int, error) {
+	// RFC 6901 does not allow leading zeros, signs or whitespace
+	if len(tok) == 0 || (len(tok) > 1 && tok[0] == '0') || strings.TrimLeft(tok, "0123456789") != "" {
+		return 0, fmt.Errorf("Invalid array index '%s'", tok)
+	}
+
+	return strconv.Atoi(tok)
+}
@@ -0,0 +1,128 @@
+package patch_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/SUSE/go-patch/patch"
+)
+
+var _ = Describe("NewJSONPointerFromString", func() {
+	It("parses RFC 6901 pointers", func() {
+		Expect(MustNewJSONPointerFromString("").Tokens()).To(BeEmpty())
+		Expect(MustNewJSONPointerFromString("/").Tokens()).To(Equal([]string{""}))
+		Expect(MustNewJSONPointerFromString("/foo/0").Tokens()).To(Equal([]string{"foo", "0"}))
+		Expect(MustNewJSONPointerFromString("/a~1b/m~0n").Tokens()).To(Equal([]string{"a/b", "m~n"}))
+		Expect(MustNewJSONPointerFromString("/~01").Tokens()).To(Equal([]string{"~1"}))
+	})
+
+	It("does not interpret go-patch specific syntax", func() {
+		Expect(MustNewJSONPointerFromString("/key?/name=val/0:before").Tokens()).To(
+			Equal([]string{"key?", "name=val", "0:before"}))
+	})
+
+	It("round trips to string", func() {
+		for _, str := range []string{"", "/", "/foo/0", "/a~1b/m~0n", "/~01", "/key?/a:b"} {
+			Expect(MustNewJSONPointerFromString(str).String()).To(Equal(str))
+		}
+	})
+
+	It("returns an error if pointer does not start with '/'", func() {
+		_, err := NewJSONPointerFromString("foo")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to start with '/'"))
+	})
+
+	It("returns an error if escape sequence is invalid", func() {
+		_, err := NewJSONPointerFromString("/foo~2")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected '~' to be followed by '0' or '1' in token 'foo~2'"))
+
+		_, err = NewJSONPointerFromString("/foo~")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("JSONPointer", func() {
+	Describe("IsProperPrefixOf", func() {
+		It("returns true only for pointers to children", func() {
+			Expect(MustNewJSONPointerFromString("/a").IsProperPrefixOf(MustNewJSONPointerFromString("/a/b"))).To(BeTrue())
+			Expect(MustNewJSONPointerFromString("").IsProperPrefixOf(MustNewJSONPointerFromString("/a"))).To(BeTrue())
+			Expect(MustNewJSONPointerFromString("/a").IsProperPrefixOf(MustNewJSONPointerFromString("/a"))).To(BeFalse())
+			Expect(MustNewJSONPointerFromString("/a").IsProperPrefixOf(MustNewJSONPointerFromString("/ab"))).To(BeFalse())
+			Expect(MustNewJSONPointerFromString("/a/b").IsProperPrefixOf(MustNewJSONPointerFromString("/a"))).To(BeFalse())
+		})
+	})
+
+	Describe("Pointer", func() {
+		doc := map[interface{}]interface{}{
+			"0":   "zero",
+			"ary": []interface{}{1, map[interface{}]interface{}{"a:b": 2}},
+		}
+
+		It("converts tokens based on document contents", func() {
+			ptr, err := MustNewJSONPointerFromString("/ary/1/a:b").Pointer(doc, false)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ptr.Tokens()).To(Equal([]Token{
+				RootToken{},
+				KeyToken{Key: "ary"},
+				IndexToken{Index: 1},
+				KeyToken{Key: "a:b"},
+			}))
+
+			ptr, err = MustNewJSONPointerFromString("/0").Pointer(doc, false)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ptr.Tokens()).To(Equal([]Token{RootToken{}, KeyToken{Key: "0"}}))
+		})
+
+		It("converts last token into insertion when requested", func() {
+			ptr, err := MustNewJSONPointerFromString("/ary/0").Pointer(doc, true)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ptr.Tokens()).To(Equal([]Token{
+				RootToken{},
+				KeyToken{Key: "ary"},
+				IndexToken{Index: 0, Modifiers: []Modifier{BeforeModifier{}}},
+			}))
+
+			ptr, err = MustNewJSONPointerFromString("/ary/2").Pointer(doc, true)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ptr.Tokens()).To(Equal([]Token{RootToken{}, KeyToken{Key: "ary"}, AfterLastIndexToken{}}))
+
+			ptr, err = MustNewJSONPointerFromString("/ary/-").Pointer(doc, true)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ptr.Tokens()).To(Equal([]Token{RootToken{}, KeyToken{Key: "ary"}, AfterLastIndexToken{}}))
+
+			ptr, err = MustNewJSONPointerFromString("/new").Pointer(doc, true)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ptr.Tokens()).To(Equal([]Token{RootToken{}, KeyToken{Key: "new", Optional: true}}))
+		})
+
+		It("returns an error if array index is invalid", func() {
+			for _, idx := range []string{"01", "-1", "+1", "a", "-"} {
+				_, err := MustNewJSONPointerFromString("/ary/"+idx).Pointer(doc, false)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Expected to find array index at path '/ary/" + idx + "' but found '" + idx + "'"))
+			}
+		})
+
+		It("returns an error if location does not exist", func() {
+			_, err := MustNewJSONPointerFromString("/ary/2").Pointer(doc, false)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected to find array index '2' but found array of length '2' for path '/ary/2'"))
+
+			_, err = MustNewJSONPointerFromString("/ary/3").Pointer(doc, true)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected to find array index '3' but found array of length '2' for path '/ary/3'"))
+
+			_, err = MustNewJSONPointerFromString("/not-there/a").Pointer(doc, true)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected to find a map key 'not-there' for path '/not-there' (found map keys: '0', 'ary')"))
+		})
+
+		It("returns an error if traversing through a scalar", func() {
+			_, err := MustNewJSONPointerFromString("/0/a").Pointer(doc, false)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected to find a map or an array at path '/0/a' but found 'string'"))
+		})
+	})
+})
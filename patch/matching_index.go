@@ -0,0 +1,241 @@
+package patch
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Conditions returns all conditions that matching item has to satisfy
+func (t MatchingIndexToken) Conditions() []MatchingCondition {
+	return append([]MatchingCondition{{Key: t.Key, Value: t.Value, Operator: t.Operator}}, t.And...)
+}
+
+// matchingIndices returns indices of array items that are maps satisfying all token conditions
+func matchingIndices(ary []interface{}, token MatchingIndexToken, m matchers) ([]int, error) {
+	return matchingIndicesFunc(len(ary), token, m, func(itemIdx int, key string) (interface{}, bool) {
+		return matchingValue(ary[itemIdx], key)
+	})
+}
+
+// matchingIndicesFunc returns indices of items satisfying all conditions.
+// Equality conditions are compared to string values; only if no items match that way,
+// they are parsed as YAML scalars and compared to other scalar values
+// (e.g. 'port=8080' matches integer 8080 unless some item has string "8080").
+// Regexp and glob conditions only match string values.
+func matchingIndicesFunc(count int, token MatchingIndexToken, m matchers, lookup func(int, string) (interface{}, bool)) ([]int, error) {
+	conds := token.Conditions()
+	condMatchers := make([]matcher, len(conds))
+
+	for i, cond := range conds {
+		condMatcher, err := m.matcher(cond)
+		if err != nil {
+			return nil, err
+		}
+		condMatchers[i] = condMatcher
+	}
+
+	matches := func(typed bool) []int {
+		var idxs []int
+
+		for itemIdx := 0; itemIdx < count; itemIdx++ {
+			matched := true
+
+			for i, cond := range conds {
+				val, found := lookup(itemIdx, cond.Key)
+				if !found {
+					matched = false
+					break
+				}
+
+				if pattern := condMatchers[i].pattern; pattern != nil {
+					str, ok := val.(string)
+					matched = ok && pattern.MatchString(str)
+				} else {
+					matched = val == cond.Value || (typed && matchesTypedValue(val, condMatchers[i].typedVal))
+				}
+
+				if !matched {
+					break
+				}
+			}
+
+			if matched {
+				idxs = append(idxs, itemIdx)
+			}
+		}
+
+		return idxs
+	}
+
+	if idxs := matches(false); len(idxs) > 0 {
+		return idxs, nil
+	}
+
+	for i, cond := range conds {
+		if !condMatchers[i].typed {
+			condMatchers[i].typedVal = parseTypedValue(cond.Value)
+		}
+	}
+
+	return matches(true), nil
+}
+
+// matchers holds matching conditions prepared ahead of time (see Compile),
+// so that regular expressions are not compiled and values are not parsed for every document.
+// Conditions that are missing (e.g. with nil matchers) are prepared whenever they are used.
+type matchers map[MatchingCondition]matcher
+
+// matcher is a matching condition prepared for comparisons
+type matcher struct {
+	pattern  *regexp.Regexp // set for regexp and glob conditions
+	typedVal *interface{}   // value of equality condition parsed as YAML scalar
+	typed    bool           // typedVal is parsed
+}
+
+func newMatcher(cond MatchingCondition) (matcher, error) {
+	pattern, err := cond.pattern()
+	if err != nil {
+		return matcher{}, err
+	}
+
+	return matcher{pattern: pattern}, nil
+}
+
+// prepare adds matcher for given condition unless it is already prepared
+func (m matchers) prepare(cond MatchingCondition) error {
+	if _, found := m[cond]; found {
+		return nil
+	}
+
+	condMatcher, err := newMatcher(cond)
+	if err != nil {
+		return err
+	}
+
+	if condMatcher.pattern == nil {
+		condMatcher.typedVal = parseTypedValue(cond.Value)
+		condMatcher.typed = true
+	}
+
+	m[cond] = condMatcher
+
+	return nil
+}
+
+func (m matchers) matcher(cond MatchingCondition) (matcher, error) {
+	if condMatcher, found := m[cond]; found {
+		return condMatcher, nil
+	}
+	return newMatcher(cond)
+}
+
+// pattern returns compiled regular expression for regexp and glob conditions
+func (c MatchingCondition) pattern() (*regexp.Regexp, error) {
+	switch c.Operator {
+	case MatchingEqual:
+		return nil, nil
+
+	case MatchingRegexp:
+		pattern, err := regexp.Compile(c.Value)
+		if err != nil {
+			return nil, fmt.Errorf("Expected to find valid regular expression for key '%s': %s", c.Key, err)
+		}
+		return pattern, nil
+
+	case MatchingGlob:
+		var expr string
+		for i, piece := range strings.Split(c.Value, "*") {
+			if i > 0 {
+				expr += ".*"
+			}
+			expr += regexp.QuoteMeta(piece)
+		}
+		return regexp.MustCompile("^" + expr + "$"), nil
+
+	default:
+		return nil, fmt.Errorf("Expected to find known matching operator for key '%s' but found '%s'", c.Key, c.Operator)
+	}
+}
+
+// matchingCreationErr returns an error if missing matching item cannot be created
+// since its contents cannot be determined from regexp or glob conditions
+func matchingCreationErr(token MatchingIndexToken, path Pointer) error {
+	for _, cond := range token.Conditions() {
+		if cond.Operator != MatchingEqual {
+			errMsg := "Expected to find matching array item for path '%s' (items cannot be created for regexp or glob conditions)"
+			return fmt.Errorf(errMsg, path)
+		}
+	}
+	return nil
+}
+
+func parseTypedValue(str string) *interface{} {
+	var val interface{}
+
+	// Empty string is not treated as null
+	if len(str) == 0 {
+		return nil
+	}
+
+	err := yaml.Unmarshal([]byte(str), &val)
+	if err != nil {
+		return nil
+	}
+
+	return &val
+}
+
+// newMatchingValue returns value satisfying equality condition: values parsed as non-string scalars
+// are typed the same way as they are compared (e.g. 'port=8080' results in integer 8080)
+func newMatchingValue(str string) interface{} {
+	typedVal := parseTypedValue(str)
+	if typedVal == nil {
+		return str
+	}
+
+	switch (*typedVal).(type) {
+	case string, map[interface{}]interface{}, []interface{}:
+		return str
+	default:
+		return *typedVal
+	}
+}
+
+// matchesTypedValue compares non-string scalar (number, boolean or null) to value parsed as YAML scalar
+func matchesTypedValue(val interface{}, typedVal *interface{}) bool {
+	if typedVal == nil {
+		return false
+	}
+
+	switch val.(type) {
+	case string, map[interface{}]interface{}, map[string]interface{}, []interface{}:
+		return false
+	}
+
+	return reflect.DeepEqual(jsonPatchNormalize(val), jsonPatchNormalize(*typedVal))
+}
+
+// matchingValue finds value by dotted key; keys that literally include dots take precedence
+func matchingValue(obj interface{}, key string) (interface{}, bool) {
+	typedObj, ok := newDocMap(obj)
+	if !ok {
+		return nil, false
+	}
+
+	if val, found := typedObj.Get(key); found {
+		return val, true
+	}
+
+	pieces := strings.SplitN(key, ".", 2)
+	if len(pieces) == 2 {
+		if child, found := typedObj.Get(pieces[0]); found {
+			return matchingValue(child, pieces[1])
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,210 @@
+package patch
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// MergeOp applies https://tools.ietf.org/html/rfc7386 merge patch at given path:
+// maps are merged recursively, nulls delete map keys and other values replace.
+// Arrays are replaced unless other array strategy is specified.
+// Path is created following ReplaceOp rules.
+type MergeOp struct {
+	Path   Pointer
+	Value  interface{} // will be deep copied (see docMapFlavor.cloneValue)
+	Arrays MergeArrays // defaults to MergeArraysReplace
+}
+
+// MergeArrays determines how arrays within merge value are combined with existing arrays
+type MergeArrays string
+
+const (
+	// MergeArraysReplace replaces existing arrays as per RFC 7386
+	MergeArraysReplace MergeArrays = "replace"
+	// MergeArraysAppend appends items to existing arrays
+	MergeArraysAppend MergeArrays = "append"
+
+	mergeArraysKeyPrefix = "key="
+)
+
+// MergeArraysByKey merges array items into existing items that have the same value
+// of given key, similarly to how '/name=val' tokens identify array items
+// (e.g. 'name' or nested 'properties.id'); other items are appended.
+func MergeArraysByKey(key string) MergeArrays {
+	return MergeArrays(mergeArraysKeyPrefix + key)
+}
+
+func (a MergeArrays) key() (string, bool) {
+	if strings.HasPrefix(string(a), mergeArraysKeyPrefix) {
+		return strings.TrimPrefix(string(a), mergeArraysKeyPrefix), true
+	}
+	return "", false
+}
+
+func (a MergeArrays) validate() error {
+	switch a {
+	case "", MergeArraysReplace, MergeArraysAppend:
+		return nil
+	}
+
+	if key, ok := a.key(); ok && len(key) > 0 {
+		return nil
+	}
+
+	errMsg := "Expected array strategy to be 'replace', 'append' or 'key=<key>' but found '%s'"
+	return fmt.Errorf(errMsg, a)
+}
+
+func (op MergeOp) path() Pointer           { return op.Path }
+func (op MergeOp) withPath(ptr Pointer) Op { op.Path = ptr; return op }
+
+func (op MergeOp) Apply(doc interface{}) (interface{}, error) {
+	return op.apply(doc, nil)
+}
+
+func (op MergeOp) apply(doc interface{}, m matchers) (interface{}, error) {
+	if res, found, err := applyWildcard(op, doc, m); found {
+		return res, err
+	}
+
+	err := op.Arrays.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure that value is not modified by future operations
+	clonedValue, err := newDocMapFlavor(doc).cloneValue(op.Value)
+	if err != nil {
+		return nil, fmt.Errorf("MergeOp cloning value: %s", err)
+	}
+
+	target, err := op.target(doc, m)
+	if err != nil {
+		return nil, err
+	}
+
+	merged, err := op.merge(target, clonedValue, op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ReplaceOp{Path: op.Path, Value: merged}.apply(doc, m)
+}
+
+func (op MergeOp) target(doc interface{}, m matchers) (interface{}, error) {
+	tokens := op.Path.Tokens()
+
+	// Inserted array items do not have existing value to merge with
+	switch typedToken := tokens[len(tokens)-1].(type) {
+	case AfterLastIndexToken:
+		return nil, nil
+	case IndexToken:
+		if op.isInsertion(typedToken.Modifiers) {
+			return nil, nil
+		}
+	case MatchingIndexToken:
+		if op.isInsertion(typedToken.Modifiers) {
+			return nil, nil
+		}
+	}
+
+	return FindOp{Path: op.Path}.apply(doc, m)
+}
+
+func (MergeOp) isInsertion(modifiers []Modifier) bool {
+	for _, modifier := range modifiers {
+		switch modifier.(type) {
+		case BeforeModifier, AfterModifier:
+			return true
+		}
+	}
+	return false
+}
+
+// merge produces maps of the same flavor as patch (ReplaceOp converts them to document's flavor)
+func (op MergeOp) merge(target, patch interface{}, path Pointer) (interface{}, error) {
+	if patchItems, ok := patch.([]interface{}); ok {
+		if targetItems, ok := target.([]interface{}); ok {
+			return op.mergeArrays(targetItems, patchItems, path)
+		}
+		return patch, nil
+	}
+
+	patchMap, ok := newDocMap(patch)
+	if !ok {
+		return patch, nil
+	}
+
+	result := map[interface{}]interface{}{}
+
+	if typedTarget, ok := newDocMap(target); ok {
+		for k, v := range typedTarget.IfaceMap() {
+			result[k] = v
+		}
+	}
+
+	for k, v := range patchMap.IfaceMap() {
+		if v == nil {
+			delete(result, k)
+			continue
+		}
+
+		merged, err := op.merge(result[k], v, op.childPath(path, KeyToken{Key: fmt.Sprintf("%v", k)}))
+		if err != nil {
+			return nil, err
+		}
+
+		result[k] = merged
+	}
+
+	return result, nil
+}
+
+func (op MergeOp) mergeArrays(target, patch []interface{}, path Pointer) (interface{}, error) {
+	key, byKey := op.Arrays.key()
+
+	if !byKey && op.Arrays != MergeArraysAppend {
+		return patch, nil
+	}
+
+	result := append([]interface{}{}, target...)
+
+	for _, item := range patch {
+		if byKey {
+			if id, found := matchingValue(item, key); found {
+				var idxs []int
+
+				for i, resultItem := range result {
+					resultID, found := matchingValue(resultItem, key)
+					if found && reflect.DeepEqual(jsonPatchNormalize(resultID), jsonPatchNormalize(id)) {
+						idxs = append(idxs, i)
+					}
+				}
+
+				if len(idxs) > 1 {
+					errMsg := "Expected to find at most one array item matching '%s=%v' to merge into for path '%s' but found %d"
+					return nil, fmt.Errorf(errMsg, key, id, path, len(idxs))
+				}
+
+				if len(idxs) == 1 {
+					merged, err := op.merge(result[idxs[0]], item, op.childPath(path, IndexToken{Index: idxs[0]}))
+					if err != nil {
+						return nil, err
+					}
+
+					result[idxs[0]] = merged
+					continue
+				}
+			}
+		}
+
+		result = append(result, item)
+	}
+
+	return result, nil
+}
+
+func (MergeOp) childPath(path Pointer, token Token) Pointer {
+	return NewPointer(append(append([]Token{}, path.Tokens()...), token))
+}
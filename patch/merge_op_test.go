@@ -0,0 +1,236 @@
+package patch_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/SUSE/go-patch/patch"
+)
+
+var _ = Describe("MergeOp.Apply", func() {
+	// Examples from https://tools.ietf.org/html/rfc7386#appendix-A
+	examples := [][]string{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	It("satisfies RFC 7386 examples at the root", func() {
+		for _, example := range examples {
+			res, err := MergeOp{
+				Path:  MustNewPointerFromString(""),
+				Value: parseYAML(example[1]),
+			}.Apply(parseYAML(example[0]))
+			Expect(err).ToNot(HaveOccurred())
+			// wrapped in an array since result may be nil
+			Expect([]interface{}{res}).To(Equal([]interface{}{parseYAML(example[2])}), "Example %#v", example)
+		}
+	})
+
+	It("satisfies RFC 7386 examples at nested path", func() {
+		for _, example := range examples {
+			doc := map[interface{}]interface{}{"nested": parseYAML(example[0])}
+
+			res, err := MergeOp{
+				Path:  MustNewPointerFromString("/nested"),
+				Value: parseYAML(example[1]),
+			}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{"nested": parseYAML(example[2])}), "Example %#v", example)
+		}
+	})
+
+	It("merges into array item", func() {
+		doc := parseYAML(`{"items":[{"name":"a","count":1},{"name":"b"}]}`)
+
+		res, err := MergeOp{
+			Path:  MustNewPointerFromString("/items/name=a"),
+			Value: parseYAML(`{"count":null,"size":2}`),
+		}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(parseYAML(`{"items":[{"name":"a","size":2},{"name":"b"}]}`)))
+	})
+
+	It("creates missing path similarly to replace operation", func() {
+		doc := parseYAML(`{"a":{}}`)
+
+		res, err := MergeOp{
+			Path:  MustNewPointerFromString("/a/b?/c"),
+			Value: parseYAML(`{"d":1,"e":null}`),
+		}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(parseYAML(`{"a":{"b":{"c":{"d":1}}}}`)))
+	})
+
+	It("creates missing array item matching key and value", func() {
+		doc := parseYAML(`{"items":[{"name":"a"}]}`)
+
+		res, err := MergeOp{
+			Path:  MustNewPointerFromString("/items/name=b?"),
+			Value: parseYAML(`{"size":2}`),
+		}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(parseYAML(`{"items":[{"name":"a"},{"name":"b","size":2}]}`)))
+	})
+
+	It("inserts array items without merging into existing items", func() {
+		doc := parseYAML(`{"items":[{"name":"a"}]}`)
+
+		res, err := MergeOp{
+			Path:  MustNewPointerFromString("/items/-"),
+			Value: parseYAML(`{"name":"b","size":null}`),
+		}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(parseYAML(`{"items":[{"name":"a"},{"name":"b"}]}`)))
+
+		res, err = MergeOp{
+			Path:  MustNewPointerFromString("/items/0:before"),
+			Value: parseYAML(`{"name":"c"}`),
+		}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(parseYAML(`{"items":[{"name":"c"},{"name":"a"},{"name":"b"}]}`)))
+	})
+
+	It("does not modify value", func() {
+		value := parseYAML(`{"a":{"b":1}}`)
+
+		res, err := MergeOp{Path: MustNewPointerFromString(""), Value: value}.Apply(parseYAML(`{}`))
+		Expect(err).ToNot(HaveOccurred())
+
+		res.(map[interface{}]interface{})["a"].(map[interface{}]interface{})["b"] = 2
+		Expect(value).To(Equal(parseYAML(`{"a":{"b":1}}`)))
+	})
+
+	It("returns an error if path does not exist", func() {
+		_, err := MergeOp{
+			Path:  MustNewPointerFromString("/b/c"),
+			Value: parseYAML(`{"d":1}`),
+		}.Apply(parseYAML(`{"a":{}}`))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find a map key 'b' for path '/b' (found map keys: 'a')"))
+	})
+
+	It("merges into every item matched by wildcard", func() {
+		res, err := MergeOp{
+			Path:  MustNewPointerFromString("/*/props"),
+			Value: parseYAML(`{"a":1}`),
+		}.Apply(parseYAML(`[{"props":{"b":2}},{"props":{"a":2}}]`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(parseYAML(`[{"props":{"a":1,"b":2}},{"props":{"a":1}}]`)))
+	})
+
+	Describe("array strategies", func() {
+		doc := func() interface{} {
+			return parseYAML(`{"jobs":[{"name":"a","props":{"x":1}},{"name":"b","tags":["t1"]}],"ports":[80]}`)
+		}
+
+		It("replaces arrays by default and with 'replace' strategy", func() {
+			for _, arrays := range []MergeArrays{"", MergeArraysReplace} {
+				res, err := MergeOp{
+					Path:   MustNewPointerFromString(""),
+					Value:  parseYAML(`{"ports":[443]}`),
+					Arrays: arrays,
+				}.Apply(doc())
+				Expect(err).ToNot(HaveOccurred())
+				Expect(res.(map[interface{}]interface{})["ports"]).To(Equal([]interface{}{443}))
+			}
+		})
+
+		It("appends items to existing arrays with 'append' strategy", func() {
+			res, err := MergeOp{
+				Path:   MustNewPointerFromString(""),
+				Value:  parseYAML(`{"ports":[443],"jobs":[{"name":"c"}],"new":[1]}`),
+				Arrays: MergeArraysAppend,
+			}.Apply(doc())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(parseYAML(`{
+				"jobs":[{"name":"a","props":{"x":1}},{"name":"b","tags":["t1"]},{"name":"c"}],
+				"ports":[80,443],
+				"new":[1]
+			}`)))
+
+			res, err = MergeOp{
+				Path:   MustNewPointerFromString("/ports"),
+				Value:  parseYAML(`[8080]`),
+				Arrays: MergeArraysAppend,
+			}.Apply(doc())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.(map[interface{}]interface{})["ports"]).To(Equal([]interface{}{80, 8080}))
+		})
+
+		It("merges items with the same key value and appends others with 'key=<key>' strategy", func() {
+			res, err := MergeOp{
+				Path:   MustNewPointerFromString("/jobs"),
+				Value:  parseYAML(`[{"name":"a","props":{"y":2,"x":null}},{"name":"b","tags":["t2"]},{"name":"c"},3]`),
+				Arrays: MergeArraysByKey("name"),
+			}.Apply(doc())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(parseYAML(`{
+				"jobs":[{"name":"a","props":{"y":2}},{"name":"b","tags":["t1","t2"]},{"name":"c"},3],
+				"ports":[80]
+			}`)))
+		})
+
+		It("identifies items by nested keys and typed values", func() {
+			res, err := MergeOp{
+				Path:   MustNewPointerFromString(""),
+				Value:  parseYAML(`[{"meta":{"id":1.0},"v":"new"}]`),
+				Arrays: MergeArraysByKey("meta.id"),
+			}.Apply(parseYAML(`[{"meta":{"id":1},"v":"old"},{"meta":{"id":2}}]`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(parseYAML(`[{"meta":{"id":1.0},"v":"new"},{"meta":{"id":2}}]`)))
+		})
+
+		It("works with documents decoded by encoding/json", func() {
+			doc := map[string]interface{}{
+				"jobs": []interface{}{map[string]interface{}{"name": "a", "x": 1}},
+			}
+
+			res, err := MergeOp{
+				Path:   MustNewPointerFromString("/jobs"),
+				Value:  []interface{}{map[string]interface{}{"name": "a", "y": 2}},
+				Arrays: MergeArraysByKey("name"),
+			}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[string]interface{}{
+				"jobs": []interface{}{map[string]interface{}{"name": "a", "x": 1, "y": 2}},
+			}))
+		})
+
+		It("returns an error if multiple existing items have the same key value", func() {
+			_, err := MergeOp{
+				Path:   MustNewPointerFromString(""),
+				Value:  parseYAML(`{"jobs":[{"name":"a"}]}`),
+				Arrays: MergeArraysByKey("name"),
+			}.Apply(parseYAML(`{"jobs":[{"name":"a"},{"name":"a"}]}`))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(
+				"Expected to find at most one array item matching 'name=a' to merge into for path '/jobs' but found 2"))
+		})
+
+		It("returns an error if strategy is unknown", func() {
+			for _, arrays := range []MergeArrays{"other", "key="} {
+				_, err := MergeOp{
+					Path:   MustNewPointerFromString(""),
+					Value:  parseYAML(`{}`),
+					Arrays: arrays,
+				}.Apply(doc())
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal(
+					"Expected array strategy to be 'replace', 'append' or 'key=<key>' but found '" + string(arrays) + "'"))
+			}
+		})
+	})
+})
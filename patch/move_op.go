@@ -1,19 +1,62 @@
 package patch
 
+import (
+	"fmt"
+	"strings"
+)
+
 type QMoveOp struct {
 	Path Pointer
 	From Pointer
 }
 
 func (op QMoveOp) Apply(doc interface{}) (interface{}, error) {
-	value, err := FindOp{Path: op.From}.Apply(doc)
+	return op.apply(doc, nil)
+}
+
+func (op QMoveOp) apply(doc interface{}, m matchers) (interface{}, error) {
+	ops, err := op.ops(doc, m)
 	if err != nil {
 		return nil, err
 	}
 
-	doc, err = ReplaceOp{Path: op.Path, Value: value}.Apply(doc)
+	return ops.apply(doc, m)
+}
+
+// ops returns operations that replace value at path with found value and then remove it
+func (op QMoveOp) ops(doc interface{}, m matchers) (Ops, error) {
+	value, err := FindOp{Path: op.From}.apply(doc, m)
 	if err != nil {
 		return nil, err
 	}
-	return RemoveOp{Path: op.From}.Apply(doc)
+
+	err = op.checkPath(doc, ifaceWalkDoc{matchers: m})
+	if err != nil {
+		return nil, err
+	}
+
+	return Ops{ReplaceOp{Path: op.Path, Value: value}, RemoveOp{Path: op.From}}, nil
+}
+
+// checkPath returns an error if found value would be moved into one of its children
+// (errors resolving either path are left to be reported by replace and remove operations)
+func (op QMoveOp) checkPath(obj interface{}, doc walkDoc) error {
+	fromLoc, err := resolveWalkDocPointer(op.From, obj, doc, false)
+	if err != nil {
+		return nil
+	}
+
+	pathLoc, err := resolveWalkDocPointer(op.Path, obj, doc, true)
+	if err != nil {
+		return nil
+	}
+
+	fromStr := NewPointer(fromLoc.tokens).String()
+	pathStr := NewPointer(pathLoc.tokens).String()
+
+	if !strings.HasPrefix(pathStr, fromStr+"/") {
+		return nil
+	}
+
+	return fmt.Errorf("Expected to not move '%s' into one of its children '%s'", op.From, op.Path)
 }
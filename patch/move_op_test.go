@@ -37,4 +37,24 @@ var _ = Describe("QMoveOp.Apply", func() {
 			}))
 		})
 	})
+
+	It("returns an error if value would be moved into one of its children", func() {
+		doc := map[interface{}]interface{}{
+			"items": []interface{}{map[interface{}]interface{}{"name": "a"}},
+		}
+
+		_, err := QMoveOp{
+			From: MustNewPointerFromString("/items"),
+			Path: MustNewPointerFromString("/items/0/sub?"),
+		}.Apply(doc)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to not move '/items' into one of its children '/items/0/sub?'"))
+
+		_, err = QMoveOp{
+			From: MustNewPointerFromString("/items/name=a"),
+			Path: MustNewPointerFromString("/items/0/sub?"),
+		}.Apply(doc)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to not move '/items/name=a' into one of its children '/items/0/sub?'"))
+	})
 })
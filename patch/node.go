@@ -0,0 +1,492 @@
+package patch
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Following functions use the same traversal as ReplaceOp, RemoveOp and FindOp
+// over yaml.v3 nodes, modifying node tree in place so that comments,
+// key order, anchors and styles of untouched nodes are preserved.
+
+func nodeReplace(doc *yaml.Node, path Pointer, value *yaml.Node) error {
+	obj := nodeRoot(doc)
+
+	if len(path.Tokens()) == 1 {
+		nodeSet(obj, value)
+		return nil
+	}
+
+	w := walker{Path: path, Doc: nodeWalkDoc{}, Missing: walkMissingCreate, Insertion: true}
+
+	err := w.Walk(obj, func(interface{}) {}, func(loc walkLocation) error {
+		parent := loc.Parent.(*yaml.Node)
+
+		switch {
+		case !loc.IsMap:
+			nodeUpdate(parent, ArrayInsertionIndex{number: loc.Index, insert: loc.Insert}, value)
+		case loc.Found:
+			nodeSet(nodeMapGet(parent, loc.Key), value)
+		default:
+			nodeMapSet(parent, loc.Key, value)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Replaced value may have contained anchors
+	nodeFixAliases(doc)
+
+	return nil
+}
+
+func nodeRemove(doc *yaml.Node, path Pointer) error {
+	if len(path.Tokens()) == 1 {
+		return fmt.Errorf("Cannot remove entire document")
+	}
+
+	w := walker{Path: path, Doc: nodeWalkDoc{}, Missing: walkMissingStop}
+
+	err := w.Walk(nodeRoot(doc), func(interface{}) {}, func(loc walkLocation) error {
+		parent := loc.Parent.(*yaml.Node)
+
+		switch {
+		case !loc.Found:
+			// Optional location does not exist, hence there is nothing to remove
+		case loc.IsMap:
+			nodeMapDelete(parent, loc.Key)
+		default:
+			parent.Content = append(parent.Content[:loc.Index:loc.Index], parent.Content[loc.Index+1:]...)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	nodeFixAliases(doc)
+
+	return nil
+}
+
+func nodeFind(doc *yaml.Node, path Pointer) (*yaml.Node, error) {
+	tokens := path.Tokens()
+	obj := nodeRoot(doc)
+
+	if len(tokens) == 1 {
+		return obj, nil
+	}
+
+	for _, token := range tokens {
+		if _, ok := token.(AfterLastIndexToken); ok {
+			errMsg := "Expected not to find after last index token in path '%s' (not supported in find operations)"
+			return nil, fmt.Errorf(errMsg, path)
+		}
+	}
+
+	w := walker{Path: path, Doc: nodeWalkDoc{}, Missing: walkMissingDetach}
+
+	err := w.Walk(obj, func(interface{}) {}, func(loc walkLocation) error {
+		switch {
+		case loc.Found:
+			foundObj, _ := w.Doc.Child(loc)
+			obj = foundObj.(*yaml.Node)
+		case loc.IsMap:
+			obj = nodeNewNull()
+		default:
+			obj = nodeNewMatchingItem(loc.Token.(MatchingIndexToken))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return obj, nil
+}
+
+// nodeWalkDoc provides access to yaml.v3 node trees; nodes are modified in place
+type nodeWalkDoc struct{}
+
+func (nodeWalkDoc) Deref(obj interface{}) interface{} { return nodeDeref(obj.(*yaml.Node)) }
+
+func (nodeWalkDoc) Items(obj interface{}) ([]interface{}, bool) {
+	node := obj.(*yaml.Node)
+	if node.Kind != yaml.SequenceNode {
+		return nil, false
+	}
+	return nodeItems(node), true
+}
+
+func (nodeWalkDoc) MatchingIndices(obj interface{}, token MatchingIndexToken) ([]int, error) {
+	return nodeMatchingIndices(obj.(*yaml.Node), token)
+}
+
+func (nodeWalkDoc) Get(obj interface{}, key string) (interface{}, bool, bool) {
+	node := obj.(*yaml.Node)
+	if node.Kind != yaml.MappingNode {
+		return nil, false, false
+	}
+
+	valNode := nodeMapGet(node, key)
+	if valNode == nil {
+		return nil, false, true
+	}
+
+	return valNode, true, true
+}
+
+func (nodeWalkDoc) ErrValue(obj interface{}) interface{} { return nodeErrValue(obj.(*yaml.Node)) }
+
+func (nodeWalkDoc) MapKeys(obj interface{}) map[interface{}]interface{} {
+	return nodeMapKeys(obj.(*yaml.Node))
+}
+
+func (nodeWalkDoc) NewArray() interface{} { return nodeNewSeq() }
+
+func (nodeWalkDoc) NewMap() interface{} { return nodeNewMap() }
+
+func (nodeWalkDoc) NewMatchingItem(token MatchingIndexToken) interface{} {
+	return nodeNewMatchingItem(token)
+}
+
+func (nodeWalkDoc) Child(loc walkLocation) (interface{}, func(interface{})) {
+	parent := loc.Parent.(*yaml.Node)
+
+	if loc.IsMap {
+		return nodeMapGet(parent, loc.Key), func(interface{}) {}
+	}
+
+	return parent.Content[loc.Index], func(interface{}) {}
+}
+
+func (nodeWalkDoc) Attach(loc walkLocation, obj interface{}) func(interface{}) {
+	parent := loc.Parent.(*yaml.Node)
+
+	if loc.IsMap {
+		nodeMapSet(parent, loc.Key, obj.(*yaml.Node))
+	} else {
+		parent.Content = append(parent.Content, obj.(*yaml.Node))
+	}
+
+	return func(interface{}) {}
+}
+
+// nodeRoot returns top level node, skipping document node
+func nodeRoot(doc *yaml.Node) *yaml.Node {
+	if doc.Kind != yaml.DocumentNode {
+		return doc
+	}
+	if len(doc.Content) == 0 {
+		doc.Content = append(doc.Content, nodeNewNull())
+	}
+	return doc.Content[0]
+}
+
+// nodeDeref follows aliases; modifications made through an alias
+// are visible via its anchor and all other aliases
+func nodeDeref(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	return node
+}
+
+// nodeFixAliases makes sure that each alias follows its anchor, since removed
+// or moved nodes may have held anchors referenced elsewhere. First alias without
+// preceding anchor takes over anchored node (keeping its own comments)
+// and anchored node becomes an alias in case it's still within the document.
+func nodeFixAliases(doc *yaml.Node) {
+	seen := map[*yaml.Node]bool{}
+
+	var fix func(*yaml.Node)
+
+	fix = func(node *yaml.Node) {
+		if node.Kind == yaml.AliasNode && node.Alias != nil {
+			target := nodeDeref(node)
+			if seen[target] {
+				node.Alias = target
+				return
+			}
+
+			anchored := *target
+			anchored.HeadComment = node.HeadComment
+			anchored.LineComment = node.LineComment
+			anchored.FootComment = node.FootComment
+
+			*target = yaml.Node{
+				Kind:        yaml.AliasNode,
+				Value:       target.Anchor,
+				Alias:       node,
+				HeadComment: target.HeadComment,
+				LineComment: target.LineComment,
+				FootComment: target.FootComment,
+			}
+
+			*node = anchored
+		}
+
+		if len(node.Anchor) > 0 {
+			seen[node] = true
+		}
+
+		for _, child := range node.Content {
+			fix(child)
+		}
+	}
+
+	fix(doc)
+}
+
+// nodeSet replaces node in place, keeping its comments and anchor
+// so that existing aliases continue to refer to it
+func nodeSet(dst, src *yaml.Node) {
+	orig := *dst
+
+	*dst = *src
+
+	if len(dst.HeadComment) == 0 {
+		dst.HeadComment = orig.HeadComment
+	}
+	if len(dst.LineComment) == 0 {
+		dst.LineComment = orig.LineComment
+	}
+	if len(dst.FootComment) == 0 {
+		dst.FootComment = orig.FootComment
+	}
+	if len(dst.Anchor) == 0 {
+		dst.Anchor = orig.Anchor
+	}
+}
+
+func nodeUpdate(seq *yaml.Node, idx ArrayInsertionIndex, value *yaml.Node) {
+	if idx.insert {
+		newContent := []*yaml.Node{}
+		newContent = append(newContent, seq.Content[:idx.number]...) // not inclusive
+		newContent = append(newContent, value)
+		newContent = append(newContent, seq.Content[idx.number:]...) // inclusive
+		seq.Content = newContent
+	} else {
+		nodeSet(seq.Content[idx.number], value)
+	}
+}
+
+// nodeCopy makes a self-contained deep copy of the node:
+// aliases are expanded and anchors are dropped to avoid duplicates
+func nodeCopy(node *yaml.Node) *yaml.Node {
+	node = nodeDeref(node)
+
+	newNode := *node
+	newNode.Anchor = ""
+	newNode.Content = nil
+
+	for _, child := range node.Content {
+		newNode.Content = append(newNode.Content, nodeCopy(child))
+	}
+
+	return &newNode
+}
+
+func nodeMatchingIndices(seq *yaml.Node, token MatchingIndexToken) ([]int, error) {
+	return matchingIndicesFunc(len(seq.Content), token, nil, func(itemIdx int, key string) (interface{}, bool) {
+		valNode := nodeMatchingValue(seq.Content[itemIdx], key)
+		switch {
+		case valNode == nil:
+			return nil, false
+		case valNode.Kind != yaml.ScalarNode:
+			return valNode, true
+		case valNode.ShortTag() == "!!str":
+			return valNode.Value, true
+		default:
+			return nodeErrValue(valNode), true
+		}
+	})
+}
+
+// nodeMatchingValue mirrors matchingValue
+func nodeMatchingValue(node *yaml.Node, key string) *yaml.Node {
+	node = nodeDeref(node)
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	if valNode := nodeMapGet(node, key); valNode != nil {
+		return nodeDeref(valNode)
+	}
+
+	pieces := strings.SplitN(key, ".", 2)
+	if len(pieces) == 2 {
+		if child := nodeMapGet(node, pieces[0]); child != nil {
+			return nodeMatchingValue(child, pieces[1])
+		}
+	}
+
+	return nil
+}
+
+// nodeNewMatchingItem mirrors docMapFlavor.NewMatchingItem
+func nodeNewMatchingItem(token MatchingIndexToken) *yaml.Node {
+	item := nodeNewMap()
+
+	for _, cond := range token.Conditions() {
+		obj := item
+		pieces := strings.Split(cond.Key, ".")
+
+		for _, piece := range pieces[:len(pieces)-1] {
+			child := nodeMapGet(obj, piece)
+			if child == nil || child.Kind != yaml.MappingNode {
+				child = nodeNewMap()
+				nodeMapDelete(obj, piece)
+				nodeMapSet(obj, piece, child)
+			}
+			obj = child
+		}
+
+		nodeMapSet(obj, pieces[len(pieces)-1], nodeNewMatchingValue(cond.Value))
+	}
+
+	return item
+}
+
+// nodeNewMatchingValue mirrors newMatchingValue
+func nodeNewMatchingValue(str string) *yaml.Node {
+	if _, ok := newMatchingValue(str).(string); !ok {
+		// Scalar keeps given representation while its tag is resolved the same way as when parsed
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: str}
+	}
+	return nodeNewStr(str)
+}
+
+func nodeMapGet(mapNode *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapNode.Content); i += 2 {
+		keyNode := mapNode.Content[i]
+		if keyNode.Kind == yaml.ScalarNode && keyNode.ShortTag() == "!!str" && keyNode.Value == key {
+			return mapNode.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func nodeMapSet(mapNode *yaml.Node, key string, value *yaml.Node) {
+	mapNode.Content = append(mapNode.Content, nodeNewStr(key), value)
+}
+
+func nodeMapDelete(mapNode *yaml.Node, key string) {
+	for i := 0; i+1 < len(mapNode.Content); i += 2 {
+		keyNode := mapNode.Content[i]
+		if keyNode.Kind == yaml.ScalarNode && keyNode.ShortTag() == "!!str" && keyNode.Value == key {
+			mapNode.Content = append(mapNode.Content[:i:i], mapNode.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+// nodeMapKeys is used to provide sibling keys in errors
+func nodeMapKeys(mapNode *yaml.Node) map[interface{}]interface{} {
+	keys := map[interface{}]interface{}{}
+	for i := 0; i+1 < len(mapNode.Content); i += 2 {
+		keys[mapNode.Content[i].Value] = nil
+	}
+	return keys
+}
+
+// nodeItems is used for array index calculations and errors
+func nodeItems(seqNode *yaml.Node) []interface{} {
+	items := make([]interface{}, len(seqNode.Content))
+	for i, item := range seqNode.Content {
+		items[i] = item
+	}
+	return items
+}
+
+func nodeNewMap() *yaml.Node { return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"} }
+func nodeNewSeq() *yaml.Node { return &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"} }
+
+func nodeNewStr(val string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: val}
+}
+
+func nodeNewNull() *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+}
+
+func nodeNewValue(value interface{}) (node *yaml.Node, err error) {
+	defer func() {
+		if recoverVal := recover(); recoverVal != nil {
+			err = fmt.Errorf("Recovered: %s", recoverVal)
+		}
+	}()
+
+	node = &yaml.Node{}
+
+	err = node.Encode(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return node, nil
+}
+
+// nodeValue decodes node into the same types yaml.v2 produces
+// (e.g. map[interface{}]interface{} for maps)
+func nodeValue(node *yaml.Node) (interface{}, error) {
+	var val interface{}
+
+	err := node.Decode(&val)
+	if err != nil {
+		return nil, err
+	}
+
+	return nodeNormalize(val), nil
+}
+
+func nodeNormalize(in interface{}) interface{} {
+	switch typedIn := in.(type) {
+	case map[string]interface{}:
+		out := map[interface{}]interface{}{}
+		for k, v := range typedIn {
+			out[k] = nodeNormalize(v)
+		}
+		return out
+
+	case map[interface{}]interface{}:
+		out := map[interface{}]interface{}{}
+		for k, v := range typedIn {
+			out[k] = nodeNormalize(v)
+		}
+		return out
+
+	case []interface{}:
+		for i, v := range typedIn {
+			typedIn[i] = nodeNormalize(v)
+		}
+		return typedIn
+
+	default:
+		return in
+	}
+}
+
+// nodeShape describes node's type and array items or map keys (without decoding map values)
+func nodeShape(node *yaml.Node) interface{} {
+	node = nodeDeref(node)
+	switch node.Kind {
+	case yaml.MappingNode:
+		return nodeMapKeys(node)
+	default:
+		return nodeErrValue(node)
+	}
+}
+
+// nodeErrValue is used to describe found value in errors
+func nodeErrValue(node *yaml.Node) interface{} {
+	val, err := nodeValue(node)
+	if err != nil {
+		return node
+	}
+	return val
+}
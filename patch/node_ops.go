@@ -0,0 +1,240 @@
+package patch
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// NodeOp is implemented by operations that are able to modify yaml.v3 node trees
+// in place, preserving comments, key order, anchors and styles of untouched parts of the document.
+type NodeOp interface {
+	ApplyNode(*yaml.Node) error
+}
+
+// Ensure basic operations implement NodeOp
+var _ NodeOp = Ops{}
+var _ NodeOp = ReplaceOp{}
+var _ NodeOp = RemoveOp{}
+var _ NodeOp = TestOp{}
+var _ NodeOp = QCopyOp{}
+var _ NodeOp = QMoveOp{}
+var _ NodeOp = DescriptiveOp{}
+var _ NodeOp = ErrOp{}
+
+// ApplyNode applies operations to a node (typically a document node)
+// produced by yaml.v3 library. All operations are expected to implement NodeOp.
+func (ops Ops) ApplyNode(node *yaml.Node) error {
+	for _, op := range ops {
+		nodeOp, ok := op.(NodeOp)
+		if !ok {
+			return fmt.Errorf("Expected operation of type '%T' to support yaml nodes", op)
+		}
+
+		err := nodeOp.ApplyNode(node)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (op ReplaceOp) ApplyNode(node *yaml.Node) error {
+	if found, err := applyWildcardNode(op, node); found {
+		return err
+	}
+
+	valueNode, err := nodeNewValue(op.Value)
+	if err != nil {
+		return fmt.Errorf("ReplaceOp encoding value: %s", err)
+	}
+
+	return nodeReplace(node, op.Path, valueNode)
+}
+
+func (op RemoveOp) ApplyNode(node *yaml.Node) error {
+	if found, err := applyWildcardNode(op, node); found {
+		return err
+	}
+
+	return nodeRemove(node, op.Path)
+}
+
+// FindNode returns found node (not a copy) within given node.
+// If path contains wildcard tokens, returned sequence node holds found nodes.
+func (op FindOp) FindNode(node *yaml.Node) (*yaml.Node, error) {
+	ptrs, found, err := wildcardPointers(op.Path, nodeWildcardFindFunc(node), nil)
+	if !found {
+		return nodeFind(node, op.Path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	seqNode := nodeNewSeq()
+
+	for _, ptr := range ptrs {
+		foundNode, err := FindOp{Path: ptr}.FindNode(node)
+		if err != nil {
+			return nil, err
+		}
+
+		// Nodes found via nested wildcards are flattened into a single sequence
+		if _, nested := wildcardIndex(ptr); nested {
+			seqNode.Content = append(seqNode.Content, foundNode.Content...)
+		} else {
+			seqNode.Content = append(seqNode.Content, foundNode)
+		}
+	}
+
+	return seqNode, nil
+}
+
+func (op TestOp) ApplyNode(node *yaml.Node) error {
+	if found, err := applyWildcardNode(op, node); found {
+		return err
+	}
+
+	if op.Absent {
+		return op.checkAbsenceNode(node)
+	}
+	return op.checkValueNode(node)
+}
+
+func (op TestOp) checkAbsenceNode(node *yaml.Node) error {
+	_, err := FindOp{Path: op.Path}.FindNode(node)
+	if err != nil {
+		if typedErr, ok := err.(OpMissingIndexErr); ok {
+			if typedErr.Path.String() == op.Path.String() {
+				return nil
+			}
+		}
+		if typedErr, ok := err.(OpMissingMapKeyErr); ok {
+			if typedErr.Path.String() == op.Path.String() {
+				return nil
+			}
+		}
+		return err
+	}
+
+	return fmt.Errorf("Expected to not find '%s'", op.Path)
+}
+
+func (op TestOp) checkValueNode(node *yaml.Node) error {
+	foundNode, err := FindOp{Path: op.Path}.FindNode(node)
+	if err != nil {
+		return err
+	}
+
+	foundVal, err := nodeValue(foundNode)
+	if err != nil {
+		return fmt.Errorf("TestOp decoding found value: %s", err)
+	}
+
+	if !op.matchesValue(foundVal) {
+		return fmt.Errorf("Found value does not match expected value")
+	}
+
+	return nil
+}
+
+// applyWildcardNode mirrors applyWildcard
+func applyWildcardNode(op pathOp, node *yaml.Node) (bool, error) {
+	ops, found, err := expandWildcardFunc(op, nodeWildcardFindFunc(node), nil)
+	if !found || err != nil {
+		return found, err
+	}
+
+	return true, ops.ApplyNode(node)
+}
+
+func nodeWildcardFindFunc(node *yaml.Node) wildcardFindFunc {
+	return func(path Pointer) (interface{}, error) {
+		foundNode, err := nodeFind(node, path)
+		if err != nil {
+			return nil, err
+		}
+		return nodeShape(foundNode), nil
+	}
+}
+
+func (op QCopyOp) ApplyNode(node *yaml.Node) error {
+	valueNode, err := FindOp{Path: op.From}.FindNode(node)
+	if err != nil {
+		return err
+	}
+
+	return nodeReplace(node, op.Path, nodeCopy(valueNode))
+}
+
+func (op QMoveOp) ApplyNode(node *yaml.Node) error {
+	valueNode, err := FindOp{Path: op.From}.FindNode(node)
+	if err != nil {
+		return err
+	}
+
+	err = op.checkPathNode(node, valueNode)
+	if err != nil {
+		return err
+	}
+
+	// Moved node keeps its comments and anchor unless an alias
+	// now precedes it (see nodeFixAliases)
+	err = nodeReplace(node, op.Path, valueNode)
+	if err != nil {
+		return err
+	}
+
+	return nodeRemove(node, op.From)
+}
+
+// checkPathNode mirrors checkPath, also taking into account that path
+// may lead into moved node via aliases (e.g. moving '/a' to '/b/c' when 'b' is an alias of 'a')
+func (op QMoveOp) checkPathNode(node, valueNode *yaml.Node) error {
+	var parent *yaml.Node
+
+	w := walker{Path: op.Path, Doc: nodeWalkDoc{}, Missing: walkMissingStop, Insertion: true}
+
+	err := w.Walk(nodeRoot(node), func(interface{}) {}, func(loc walkLocation) error {
+		parent = loc.Parent.(*yaml.Node)
+		return nil
+	})
+	if err != nil || parent == nil {
+		return nil // reported by replace
+	}
+
+	if nodeContains(valueNode, parent) {
+		return fmt.Errorf("Expected to not move '%s' into one of its children '%s'", op.From, op.Path)
+	}
+
+	return nil
+}
+
+// nodeContains returns true if node is or contains other node (aliases are not followed
+// since they do not make node tree cyclic)
+func nodeContains(node, other *yaml.Node) bool {
+	if node == other {
+		return true
+	}
+
+	for _, child := range node.Content {
+		if nodeContains(child, other) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (op DescriptiveOp) ApplyNode(node *yaml.Node) error {
+	err := Ops{op.Op}.ApplyNode(node)
+	if err != nil {
+		return fmt.Errorf("Error '%s': %w", op.ErrorMsg, err)
+	}
+	return nil
+}
+
+func (op ErrOp) ApplyNode(_ *yaml.Node) error {
+	return op.Err
+}
@@ -0,0 +1,356 @@
+package patch_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	yamlv2 "gopkg.in/yaml.v2"
+	"gopkg.in/yaml.v3"
+
+	. "github.com/SUSE/go-patch/patch"
+)
+
+var _ = Describe("Ops.ApplyNode", func() {
+	apply := func(docStr, opsStr string) (string, error) {
+		var doc yaml.Node
+
+		err := yaml.Unmarshal([]byte(docStr), &doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		var opDefs []OpDefinition
+
+		err = yamlv2.Unmarshal([]byte(opsStr), &opDefs)
+		Expect(err).ToNot(HaveOccurred())
+
+		ops, err := NewOpsFromDefinitions(opDefs)
+		Expect(err).ToNot(HaveOccurred())
+
+		err = ops.ApplyNode(&doc)
+		if err != nil {
+			return "", err
+		}
+
+		bytes, err := yaml.Marshal(&doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		return string(bytes), nil
+	}
+
+	It("preserves comments, key order, anchors and styles of untouched parts", func() {
+		res, err := apply(`# Deployment manifest
+name: dep # deployment name
+
+releases: &releases
+- name: capi
+  version: "0.1"
+
+instance_groups:
+- name: zookeeper
+  instances: 1 # scaled later
+  azs: [z1, z2]
+- name: uaa
+  instances: 0
+  releases: *releases
+`, `
+- type: replace
+  path: /instance_groups/name=zookeeper/instances
+  value: 3
+- type: replace
+  path: /instance_groups/name=zookeeper/azs/-
+  value: z3
+- type: remove
+  path: /instance_groups/name=uaa/instances
+- type: replace
+  path: /instance_groups/name=uaa/jobs?/name=uaa/release
+  value: uaa
+- type: replace
+  path: /releases/name=capi/version
+  value: latest
+`)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(`# Deployment manifest
+name: dep # deployment name
+releases: &releases
+    - name: capi
+      version: latest
+instance_groups:
+    - name: zookeeper
+      instances: 3 # scaled later
+      azs: [z1, z2, z3]
+    - name: uaa
+      releases: *releases
+      jobs:
+        - name: uaa
+          release: uaa
+`))
+	})
+
+	It("replaces entire document", func() {
+		res, err := apply("a: 1\n", "- type: replace\n  path: \"\"\n  value: {b: 2}\n")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal("b: 2\n"))
+	})
+
+	It("inserts array items relative to other items", func() {
+		res, err := apply("- name: a\n- name: b\n", `
+- type: replace
+  path: /name=a:after
+  value: {name: c}
+- type: replace
+  path: /0:before
+  value: {name: d}
+`)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal("- name: d\n- name: a\n- name: c\n- name: b\n"))
+	})
+
+	It("applies operations with wildcards to every array item or map value", func() {
+		res, err := apply("igs:\n- name: a # first\n- name: b\n  disk: 1\n", `
+- type: replace
+  path: /igs/*/disk?
+  value: 10
+- type: remove
+  path: /igs/*/name
+- type: test
+  path: /igs/*/disk
+  value: 10
+`)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal("igs:\n    - disk: 10\n    - disk: 10\n"))
+	})
+
+	It("matches and creates array items with multiple conditions or nested keys", func() {
+		res, err := apply("- {name: a, props: {port: \"80\"}}\n- {name: a, props: {port: \"81\"}}\n", `
+- type: replace
+  path: /name=a,props.port=81/v?
+  value: 1
+- type: replace
+  path: /name=b,props.port=82?/v
+  value: 2
+`)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(`- {name: a, props: {port: "80"}}
+- {name: a, props: {port: "81"}, v: 1}
+- name: b
+  props:
+    port: 82
+  v: 2
+`))
+	})
+
+	It("copies and moves values", func() {
+		res, err := apply("a: &anchor\n  b: 1 # comment\nc: *anchor\n", `
+- type: qcopy
+  from: /c
+  path: /d?
+- type: qmove
+  from: /a
+  path: /e?
+`)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal("c: &anchor\n    b: 1 # comment\nd:\n    b: 1 # comment\ne: *anchor\n"))
+	})
+
+	It("keeps aliases of removed or moved anchors valid", func() {
+		res, err := apply("a: &x {b: &y 1}\nc: *x\nd: *y\ne: *x\n", "- type: remove\n  path: /a\n")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal("c: &x {b: &y 1}\nd: *y\ne: *x\n"))
+		Expect(parseYAML(res)).To(Equal(map[interface{}]interface{}{
+			"c": map[interface{}]interface{}{"b": 1},
+			"d": 1,
+			"e": map[interface{}]interface{}{"b": 1},
+		}))
+
+		res, err = apply("a: {b: &y 1}\nc: *y\n", "- type: replace\n  path: /a\n  value: 2\n")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal("a: 2\nc: &y 1\n"))
+		Expect(parseYAML(res)).To(Equal(map[interface{}]interface{}{"a": 2, "c": 1}))
+
+		res, err = apply("a: [&x 1, 2]\nb: [*x]\n", "- type: qmove\n  from: /a/0\n  path: /c?\n")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal("a: [2]\nb: [&x 1]\nc: *x\n"))
+		Expect(parseYAML(res)).To(Equal(map[interface{}]interface{}{
+			"a": []interface{}{2},
+			"b": []interface{}{1},
+			"c": 1,
+		}))
+	})
+
+	It("returns an error if node would be moved into one of its children", func() {
+		_, err := apply("items: [{name: a}]\n", "- type: qmove\n  from: /items\n  path: /items/0/sub?\n")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to not move '/items' into one of its children '/items/0/sub?'"))
+
+		_, err = apply("a: &a {b: 1}\nc: *a\n", "- type: qmove\n  from: /a\n  path: /c/d?\n")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to not move '/a' into one of its children '/c/d?'"))
+	})
+
+	It("tests values and absence", func() {
+		_, err := apply("a: {b: [1, 2]}\n", `
+- type: test
+  path: /a
+  value: {b: [1, 2]}
+- type: test
+  path: /a/c
+  absent: true
+`)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = apply("a: {b: [1, 2]}\n", `
+- type: test
+  path: /a/b/0
+  value: 2
+`)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Found value does not match expected value"))
+
+		_, err = apply("a: {b: [1, 2]}\n", `
+- type: test
+  path: /a
+  absent: true
+`)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to not find '/a'"))
+	})
+
+	It("tests values regardless of their map flavor and number types", func() {
+		var doc yaml.Node
+
+		err := yaml.Unmarshal([]byte("a: {b: [1.5, c]}\n"), &doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		value := map[string]interface{}{"b": []interface{}{float64(1.5), "c"}}
+
+		err = TestOp{Path: MustNewPointerFromString("/a"), Value: value}.ApplyNode(&doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = TestOp{Path: MustNewPointerFromString("/a"), Value: value}.Apply(map[interface{}]interface{}{
+			"a": map[interface{}]interface{}{"b": []interface{}{1.5, "c"}},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		err = TestOp{Path: MustNewPointerFromString("/a/b/0"), Value: float64(1.5)}.ApplyNode(&doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		err = yaml.Unmarshal([]byte("a: {b: [1, {port: 8080}]}\n"), &doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		err = TestOp{Path: MustNewPointerFromString("/a"), Value: parseJSON(`{"b":[1,{"port":8080}]}`)}.ApplyNode(&doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		err = TestOp{Path: MustNewPointerFromString("/a/b/0"), Value: float64(2)}.ApplyNode(&doc)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Found value does not match expected value"))
+	})
+
+	It("prefers string values in key=val tokens like yaml.v2 documents", func() {
+		_, err := apply("- port: 80\n- port: \"80\"\n", "- type: remove\n  path: /port=80\n")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("matches typed values if no string values match", func() {
+		res, err := apply("- port: 80\n- port: 8080 # typed\n", "- type: remove\n  path: /port=80\n")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal("- port: 8080 # typed\n"))
+	})
+
+	It("creates missing array items with typed values that are matched when applied again", func() {
+		ops := "- type: replace\n  path: /port=8080?/v\n  value: 1\n"
+
+		res, err := apply("- port: 80\n", ops)
+		Expect(err).ToNot(HaveOccurred())
+
+		res, err = apply(res, ops)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal("- port: 80\n- port: 8080\n  v: 1\n"))
+	})
+
+	It("matches array items with regexp or glob conditions", func() {
+		res, err := apply("- name: worker-az1 # w\n- name: api-az1\n", `
+- type: replace
+  path: /name~=^worker-/v?
+  value: 1
+- type: remove
+  path: /name*=api-*
+`)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal("- name: worker-az1 # w\n  v: 1\n"))
+
+		_, err = apply("- name: api-az1\n", "- type: replace\n  path: /name~=^db-:optional/v\n  value: 1\n")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal(
+			"Expected to find matching array item for path '/name~=^db-:optional' (items cannot be created for regexp or glob conditions)"))
+	})
+
+	It("returns the same errors as operations on yaml.v2 documents", func() {
+		_, err := apply("releases:\n- name: capi\n", `
+- type: remove
+  path: /releases/0/not-there
+  error: "Custom error message"
+`)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal(
+			"Error 'Custom error message': Expected to find a map key 'not-there' for path '/releases/0/not-there' (found map keys: 'name')"))
+
+		var missingKeyErr OpMissingMapKeyErr
+		Expect(errors.As(err, &missingKeyErr)).To(BeTrue())
+		Expect(missingKeyErr.Key).To(Equal("not-there"))
+
+		_, err = apply("releases: [1]\n", "- type: replace\n  path: /releases/2\n  value: 1\n")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find array index '2' but found array of length '1' for path '/releases/2'"))
+
+		_, err = apply("releases: [1]\n", "- type: replace\n  path: /releases/a\n  value: 1\n")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find a map at path '/releases/a' but found '[]interface {}'"))
+
+		_, err = apply("- {name: a}\n- {name: a}\n", "- type: remove\n  path: /name=a\n")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find exactly one matching array item for path '/name=a' but found 2"))
+
+		_, err = apply("a: 1\n", "- type: remove\n  path: \"\"\n")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Cannot remove entire document"))
+	})
+
+	It("returns an error if operation does not support nodes", func() {
+		var doc yaml.Node
+
+		err := Ops{ErrOp{errors.New("fake-err")}}.ApplyNode(&doc)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("fake-err"))
+
+		err = Ops{FindOp{Path: MustNewPointerFromString("")}}.ApplyNode(&doc)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected operation of type 'patch.FindOp' to support yaml nodes"))
+	})
+})
+
+var _ = Describe("FindOp.FindNode", func() {
+	It("returns found node", func() {
+		var doc yaml.Node
+
+		err := yaml.Unmarshal([]byte("a: [{name: b, c: 1}]\n"), &doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		node, err := FindOp{Path: MustNewPointerFromString("/a/name=b/c")}.FindNode(&doc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(node.Value).To(Equal("1"))
+		Expect(node.Line).To(Equal(1))
+	})
+
+	It("returns sequence of found nodes if path contains wildcards", func() {
+		var doc yaml.Node
+
+		err := yaml.Unmarshal([]byte("a: [{b: [1, 2]}, {b: [3]}]\n"), &doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		node, err := FindOp{Path: MustNewPointerFromString("/a/*/b/*")}.FindNode(&doc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(node.Kind).To(Equal(yaml.SequenceNode))
+		Expect(node.Content).To(HaveLen(3))
+		Expect(node.Content[2].Value).To(Equal("3"))
+	})
+})
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"sync"
 )
 
 // OpDefinition struct is useful for JSON and YAML unmarshaling
@@ -13,11 +14,109 @@ type OpDefinition struct {
 	From   *string      `json:",omitempty" yaml:",omitempty"`
 	Value  *interface{} `json:",omitempty" yaml:",omitempty"`
 	Absent *bool        `json:",omitempty" yaml:",omitempty"`
+	Arrays *string      `json:",omitempty" yaml:",omitempty"`
+	Key    *string      `json:",omitempty" yaml:",omitempty"`
+	Index  *int         `json:",omitempty" yaml:",omitempty"`
 	Error  *string      `json:",omitempty" yaml:",omitempty"`
 }
 
 type parser struct{}
 
+// OpParseFunc builds an operation from its definition
+type OpParseFunc func(OpDefinition) (Op, error)
+
+// OpSerializeFunc builds a definition for an operation (Type is filled in automatically);
+// false is returned if operation is not of the registered type
+type OpSerializeFunc func(Op) (OpDefinition, bool)
+
+type opType struct {
+	Name      string
+	Parse     OpParseFunc
+	Serialize OpSerializeFunc
+}
+
+// builtinOpType parses built-in operation type; its name cannot be registered
+type builtinOpType struct {
+	Name    string
+	ErrName string // used in error messages
+	Parse   func(parser, OpDefinition) (Op, error)
+}
+
+var (
+	builtinOpTypes = []builtinOpType{
+		{"replace", "Replace", func(p parser, opDef OpDefinition) (Op, error) { return p.newReplaceOp(opDef) }},
+		{"add", "Add", func(p parser, opDef OpDefinition) (Op, error) { return p.newAddOp(opDef) }},
+		{"default", "Default", func(p parser, opDef OpDefinition) (Op, error) { return p.newDefaultOp(opDef) }},
+		{"remove", "Remove", func(p parser, opDef OpDefinition) (Op, error) { return p.newRemoveOp(opDef) }},
+		{"test", "Test", func(p parser, opDef OpDefinition) (Op, error) { return p.newTestOp(opDef) }},
+		{"qcopy", "QCopy", func(p parser, opDef OpDefinition) (Op, error) { return p.newQCopyOp(opDef) }},
+		{"qmove", "QMove", func(p parser, opDef OpDefinition) (Op, error) { return p.newQMoveOp(opDef) }},
+		{"merge", "Merge", func(p parser, opDef OpDefinition) (Op, error) { return p.newMergeOp(opDef) }},
+		{"append-unique", "AppendUnique", func(p parser, opDef OpDefinition) (Op, error) { return p.newAppendUniqueOp(opDef) }},
+		{"remove-value", "RemoveValue", func(p parser, opDef OpDefinition) (Op, error) { return p.newRemoveValueOp(opDef) }},
+		{"sort", "Sort", func(p parser, opDef OpDefinition) (Op, error) { return p.newSortOp(opDef) }},
+		{"dedupe", "Dedupe", func(p parser, opDef OpDefinition) (Op, error) { return p.newDedupeOp(opDef) }},
+		{"reorder", "Reorder", func(p parser, opDef OpDefinition) (Op, error) { return p.newReorderOp(opDef) }},
+	}
+
+	opTypesLock sync.RWMutex
+	opTypes     []opType
+)
+
+// RegisterOpType makes custom operation type available to NewOpsFromDefinitions
+// and NewOpDefinitionsFromOps. Serialize function may be nil if operations
+// of this type do not need to be converted back to definitions.
+// It panics if type name is empty, built-in or already registered.
+func RegisterOpType(name string, parse OpParseFunc, serialize OpSerializeFunc) {
+	if len(name) == 0 {
+		panic("Expected operation type name to be non-empty")
+	}
+
+	if parse == nil {
+		panic(fmt.Sprintf("Expected parse function for operation type '%s'", name))
+	}
+
+	if _, found := builtinOpTypeByName(name); found {
+		panic(fmt.Sprintf("Expected operation type '%s' to not be built-in", name))
+	}
+
+	opTypesLock.Lock()
+	defer opTypesLock.Unlock()
+
+	for _, t := range opTypes {
+		if t.Name == name {
+			panic(fmt.Sprintf("Expected operation type '%s' to be registered once", name))
+		}
+	}
+
+	opTypes = append(opTypes, opType{Name: name, Parse: parse, Serialize: serialize})
+}
+
+func builtinOpTypeByName(name string) (builtinOpType, bool) {
+	for _, t := range builtinOpTypes {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return builtinOpType{}, false
+}
+
+func registeredOpTypes() []opType {
+	opTypesLock.RLock()
+	defer opTypesLock.RUnlock()
+
+	return opTypes
+}
+
+func registeredOpType(name string) (opType, bool) {
+	for _, t := range registeredOpTypes() {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return opType{}, false
+}
+
 func NewOpsFromDefinitions(opDefs []OpDefinition) (Ops, error) {
 	var ops []Op
 	var p parser
@@ -28,39 +127,21 @@ func NewOpsFromDefinitions(opDefs []OpDefinition) (Ops, error) {
 
 		opFmt := p.fmtOpDef(opDef)
 
-		switch opDef.Type {
-		case "replace":
-			op, err = p.newReplaceOp(opDef)
-			if err != nil {
-				return nil, fmt.Errorf("Replace operation [%d]: %s within\n%s", i, err, opFmt)
-			}
-
-		case "remove":
-			op, err = p.newRemoveOp(opDef)
+		if t, found := builtinOpTypeByName(opDef.Type); found {
+			op, err = t.Parse(p, opDef)
 			if err != nil {
-				return nil, fmt.Errorf("Remove operation [%d]: %s within\n%s", i, err, opFmt)
+				return nil, fmt.Errorf("%s operation [%d]: %s within\n%s", t.ErrName, i, err, opFmt)
 			}
-
-		case "test":
-			op, err = p.newTestOp(opDef)
-			if err != nil {
-				return nil, fmt.Errorf("Test operation [%d]: %s within\n%s", i, err, opFmt)
+		} else {
+			t, found := registeredOpType(opDef.Type)
+			if !found {
+				return nil, fmt.Errorf("Unknown operation [%d] with type '%s' within\n%s", i, opDef.Type, opFmt)
 			}
 
-		case "qcopy":
-			op, err = p.newQCopyOp(opDef)
+			op, err = t.Parse(opDef)
 			if err != nil {
-				return nil, fmt.Errorf("QCopy operation [%d]: %s within\n%s", i, err, opFmt)
+				return nil, fmt.Errorf("Operation [%d] with type '%s': %s within\n%s", i, opDef.Type, err, opFmt)
 			}
-
-		case "qmove":
-			op, err = p.newQMoveOp(opDef)
-			if err != nil {
-				return nil, fmt.Errorf("QMove operation [%d]: %s within\n%s", i, err, opFmt)
-			}
-
-		default:
-			return nil, fmt.Errorf("Unknown operation [%d] with type '%s' within\n%s", i, opDef.Type, opFmt)
 		}
 
 		if opDef.Error != nil {
@@ -90,6 +171,40 @@ func (parser) newReplaceOp(opDef OpDefinition) (ReplaceOp, error) {
 	return ReplaceOp{Path: ptr, Value: *opDef.Value}, nil
 }
 
+func (parser) newAddOp(opDef OpDefinition) (AddOp, error) {
+	if opDef.Path == nil {
+		return AddOp{}, fmt.Errorf("Missing path")
+	}
+
+	if opDef.Value == nil {
+		return AddOp{}, fmt.Errorf("Missing value")
+	}
+
+	ptr, err := NewPointerFromString(*opDef.Path)
+	if err != nil {
+		return AddOp{}, fmt.Errorf("Invalid path: %s", err)
+	}
+
+	return AddOp{Path: ptr, Value: *opDef.Value}, nil
+}
+
+func (parser) newDefaultOp(opDef OpDefinition) (DefaultOp, error) {
+	if opDef.Path == nil {
+		return DefaultOp{}, fmt.Errorf("Missing path")
+	}
+
+	if opDef.Value == nil {
+		return DefaultOp{}, fmt.Errorf("Missing value")
+	}
+
+	ptr, err := NewPointerFromString(*opDef.Path)
+	if err != nil {
+		return DefaultOp{}, fmt.Errorf("Invalid path: %s", err)
+	}
+
+	return DefaultOp{Path: ptr, Value: *opDef.Value}, nil
+}
+
 func (parser) newRemoveOp(opDef OpDefinition) (RemoveOp, error) {
 	if opDef.Path == nil {
 		return RemoveOp{}, fmt.Errorf("Missing path")
@@ -186,6 +301,141 @@ func (parser) newQMoveOp(opDef OpDefinition) (QMoveOp, error) {
 	return QMoveOp{Path: pathPtr, From: fromPtr}, nil
 }
 
+func (parser) newMergeOp(opDef OpDefinition) (MergeOp, error) {
+	if opDef.Path == nil {
+		return MergeOp{}, fmt.Errorf("Missing path")
+	}
+
+	if opDef.Value == nil {
+		return MergeOp{}, fmt.Errorf("Missing value")
+	}
+
+	ptr, err := NewPointerFromString(*opDef.Path)
+	if err != nil {
+		return MergeOp{}, fmt.Errorf("Invalid path: %s", err)
+	}
+
+	op := MergeOp{Path: ptr, Value: *opDef.Value}
+
+	if opDef.Arrays != nil {
+		op.Arrays = MergeArrays(*opDef.Arrays)
+
+		err := op.Arrays.validate()
+		if err != nil {
+			return MergeOp{}, fmt.Errorf("Invalid arrays: %s", err)
+		}
+	}
+
+	return op, nil
+}
+
+func (parser) newAppendUniqueOp(opDef OpDefinition) (AppendUniqueOp, error) {
+	if opDef.Path == nil {
+		return AppendUniqueOp{}, fmt.Errorf("Missing path")
+	}
+
+	if opDef.Value == nil {
+		return AppendUniqueOp{}, fmt.Errorf("Missing value")
+	}
+
+	ptr, err := NewPointerFromString(*opDef.Path)
+	if err != nil {
+		return AppendUniqueOp{}, fmt.Errorf("Invalid path: %s", err)
+	}
+
+	op := AppendUniqueOp{Path: ptr, Value: *opDef.Value}
+
+	if opDef.Key != nil {
+		op.Key = *opDef.Key
+	}
+
+	return op, nil
+}
+
+func (parser) newRemoveValueOp(opDef OpDefinition) (RemoveValueOp, error) {
+	if opDef.Path == nil {
+		return RemoveValueOp{}, fmt.Errorf("Missing path")
+	}
+
+	if opDef.Value == nil {
+		return RemoveValueOp{}, fmt.Errorf("Missing value")
+	}
+
+	ptr, err := NewPointerFromString(*opDef.Path)
+	if err != nil {
+		return RemoveValueOp{}, fmt.Errorf("Invalid path: %s", err)
+	}
+
+	return RemoveValueOp{Path: ptr, Value: *opDef.Value}, nil
+}
+
+func (parser) newSortOp(opDef OpDefinition) (SortOp, error) {
+	if opDef.Path == nil {
+		return SortOp{}, fmt.Errorf("Missing path")
+	}
+
+	if opDef.Value != nil {
+		return SortOp{}, fmt.Errorf("Cannot specify value")
+	}
+
+	ptr, err := NewPointerFromString(*opDef.Path)
+	if err != nil {
+		return SortOp{}, fmt.Errorf("Invalid path: %s", err)
+	}
+
+	op := SortOp{Path: ptr}
+
+	if opDef.Key != nil {
+		op.Key = *opDef.Key
+	}
+
+	return op, nil
+}
+
+func (parser) newDedupeOp(opDef OpDefinition) (DedupeOp, error) {
+	if opDef.Path == nil {
+		return DedupeOp{}, fmt.Errorf("Missing path")
+	}
+
+	if opDef.Value != nil {
+		return DedupeOp{}, fmt.Errorf("Cannot specify value")
+	}
+
+	ptr, err := NewPointerFromString(*opDef.Path)
+	if err != nil {
+		return DedupeOp{}, fmt.Errorf("Invalid path: %s", err)
+	}
+
+	op := DedupeOp{Path: ptr}
+
+	if opDef.Key != nil {
+		op.Key = *opDef.Key
+	}
+
+	return op, nil
+}
+
+func (parser) newReorderOp(opDef OpDefinition) (ReorderOp, error) {
+	if opDef.Path == nil {
+		return ReorderOp{}, fmt.Errorf("Missing path")
+	}
+
+	if opDef.Index == nil {
+		return ReorderOp{}, fmt.Errorf("Missing index")
+	}
+
+	if opDef.Value != nil {
+		return ReorderOp{}, fmt.Errorf("Cannot specify value")
+	}
+
+	ptr, err := NewPointerFromString(*opDef.Path)
+	if err != nil {
+		return ReorderOp{}, fmt.Errorf("Invalid path: %s", err)
+	}
+
+	return ReorderOp{Path: ptr, Index: *opDef.Index}, nil
+}
+
 func (parser) fmtOpDef(opDef OpDefinition) string {
 	var (
 		redactedVal interface{} = "<redacted>"
@@ -205,6 +455,25 @@ func (parser) fmtOpDef(opDef OpDefinition) string {
 	return htmlDecoder.Replace(string(bytes))
 }
 
+// fmtOp formats operation the same way as its definition (or only its type if it cannot be serialized)
+func fmtOp(op Op) string {
+	var errMsg *string
+
+	if descOp, ok := op.(DescriptiveOp); ok {
+		op = descOp.Op
+		errMsg = &descOp.ErrorMsg
+	}
+
+	opDefs, err := NewOpDefinitionsFromOps(Ops{op})
+	if err != nil || len(opDefs) != 1 {
+		return fmt.Sprintf("<%T>", op)
+	}
+
+	opDefs[0].Error = errMsg
+
+	return parser{}.fmtOpDef(opDefs[0])
+}
+
 func NewOpDefinitionsFromOps(ops Ops) ([]OpDefinition, error) {
 	opDefs := []OpDefinition{}
 
@@ -220,6 +489,26 @@ func NewOpDefinitionsFromOps(ops Ops) ([]OpDefinition, error) {
 				Value: &val,
 			})
 
+		case AddOp:
+			path := typedOp.Path.String()
+			val := typedOp.Value
+
+			opDefs = append(opDefs, OpDefinition{
+				Type:  "add",
+				Path:  &path,
+				Value: &val,
+			})
+
+		case DefaultOp:
+			path := typedOp.Path.String()
+			val := typedOp.Value
+
+			opDefs = append(opDefs, OpDefinition{
+				Type:  "default",
+				Path:  &path,
+				Value: &val,
+			})
+
 		case RemoveOp:
 			path := typedOp.Path.String()
 
@@ -265,10 +554,103 @@ func NewOpDefinitionsFromOps(ops Ops) ([]OpDefinition, error) {
 				From: &from,
 			})
 
+		case MergeOp:
+			path := typedOp.Path.String()
+			val := typedOp.Value
+
+			opDef := OpDefinition{
+				Type:  "merge",
+				Path:  &path,
+				Value: &val,
+			}
+
+			if len(typedOp.Arrays) > 0 {
+				arrays := string(typedOp.Arrays)
+				opDef.Arrays = &arrays
+			}
+
+			opDefs = append(opDefs, opDef)
+
+		case AppendUniqueOp:
+			path := typedOp.Path.String()
+			val := typedOp.Value
+
+			opDef := OpDefinition{
+				Type:  "append-unique",
+				Path:  &path,
+				Value: &val,
+			}
+
+			if len(typedOp.Key) > 0 {
+				opDef.Key = &typedOp.Key
+			}
+
+			opDefs = append(opDefs, opDef)
+
+		case RemoveValueOp:
+			path := typedOp.Path.String()
+			val := typedOp.Value
+
+			opDefs = append(opDefs, OpDefinition{
+				Type:  "remove-value",
+				Path:  &path,
+				Value: &val,
+			})
+
+		case SortOp:
+			path := typedOp.Path.String()
+			opDef := OpDefinition{Type: "sort", Path: &path}
+
+			if len(typedOp.Key) > 0 {
+				opDef.Key = &typedOp.Key
+			}
+
+			opDefs = append(opDefs, opDef)
+
+		case DedupeOp:
+			path := typedOp.Path.String()
+			opDef := OpDefinition{Type: "dedupe", Path: &path}
+
+			if len(typedOp.Key) > 0 {
+				opDef.Key = &typedOp.Key
+			}
+
+			opDefs = append(opDefs, opDef)
+
+		case ReorderOp:
+			path := typedOp.Path.String()
+			index := typedOp.Index
+
+			opDefs = append(opDefs, OpDefinition{
+				Type:  "reorder",
+				Path:  &path,
+				Index: &index,
+			})
+
 		default:
-			return nil, fmt.Errorf("Unknown operation [%d] with type '%t'", i, op)
+			opDef, found := serializeRegisteredOp(op)
+			if !found {
+				return nil, fmt.Errorf("Unknown operation [%d] with type '%T'", i, op)
+			}
+
+			opDefs = append(opDefs, opDef)
 		}
 	}
 
 	return opDefs, nil
 }
+
+func serializeRegisteredOp(op Op) (OpDefinition, bool) {
+	for _, t := range registeredOpTypes() {
+		if t.Serialize == nil {
+			continue
+		}
+
+		opDef, ok := t.Serialize(op)
+		if ok {
+			opDef.Type = t.Name
+			return opDef, true
+		}
+	}
+	return OpDefinition{}, false
+}
@@ -2,6 +2,8 @@ package patch_test
 
 import (
 	"encoding/json"
+	"errors"
+	"strings"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -20,16 +22,27 @@ var _ = Describe("NewOpsFromDefinitions", func() {
 		val         interface{} = 123
 		complexVal  interface{} = map[interface{}]interface{}{123: 123}
 		trueBool                = true
+		key                     = "name"
+		index                   = -1
 	)
 
-	It("supports 'replace', 'remove', 'test', 'qcopy', 'qmove' operations", func() {
+	It("supports 'replace', 'add', 'default', 'remove', 'test', 'qcopy', 'qmove', 'merge', 'append-unique', 'remove-value', 'sort', 'dedupe', 'reorder' operations", func() {
 		opDefs := []OpDefinition{
 			{Type: "replace", Path: &path, Value: &val},
+			{Type: "add", Path: &path, Value: &val},
+			{Type: "default", Path: &path, Value: &val},
 			{Type: "remove", Path: &path},
 			{Type: "test", Path: &path, Value: &val},
 			{Type: "test", Path: &path, Absent: &trueBool},
 			{Type: "qcopy", Path: &path, From: &from},
 			{Type: "qmove", Path: &path, From: &from},
+			{Type: "merge", Path: &path, Value: &val},
+			{Type: "append-unique", Path: &path, Value: &val},
+			{Type: "append-unique", Path: &path, Value: &val, Key: &key},
+			{Type: "remove-value", Path: &path, Value: &val},
+			{Type: "sort", Path: &path, Key: &key},
+			{Type: "dedupe", Path: &path},
+			{Type: "reorder", Path: &path, Index: &index},
 		}
 
 		ops, err := NewOpsFromDefinitions(opDefs)
@@ -37,11 +50,20 @@ var _ = Describe("NewOpsFromDefinitions", func() {
 
 		Expect(ops).To(Equal(Ops([]Op{
 			ReplaceOp{Path: MustNewPointerFromString("/abc"), Value: 123},
+			AddOp{Path: MustNewPointerFromString("/abc"), Value: 123},
+			DefaultOp{Path: MustNewPointerFromString("/abc"), Value: 123},
 			RemoveOp{Path: MustNewPointerFromString("/abc")},
 			TestOp{Path: MustNewPointerFromString("/abc"), Value: 123},
 			TestOp{Path: MustNewPointerFromString("/abc"), Absent: true},
 			QCopyOp{Path: MustNewPointerFromString("/abc"), From: MustNewPointerFromString("/abc")},
 			QMoveOp{Path: MustNewPointerFromString("/abc"), From: MustNewPointerFromString("/abc")},
+			MergeOp{Path: MustNewPointerFromString("/abc"), Value: 123},
+			AppendUniqueOp{Path: MustNewPointerFromString("/abc"), Value: 123},
+			AppendUniqueOp{Path: MustNewPointerFromString("/abc"), Value: 123, Key: "name"},
+			RemoveValueOp{Path: MustNewPointerFromString("/abc"), Value: 123},
+			SortOp{Path: MustNewPointerFromString("/abc"), Key: "name"},
+			DedupeOp{Path: MustNewPointerFromString("/abc")},
+			ReorderOp{Path: MustNewPointerFromString("/abc"), Index: -1},
 		})))
 	})
 
@@ -117,6 +139,98 @@ var _ = Describe("NewOpsFromDefinitions", func() {
 		})
 	})
 
+	Describe("add", func() {
+		It("allows error description", func() {
+			opDefs := []OpDefinition{{Type: "add", Path: &path, Value: &val, Error: &errorMsg}}
+
+			ops, err := NewOpsFromDefinitions(opDefs)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ops).To(Equal(Ops([]Op{
+				DescriptiveOp{
+					Op:       AddOp{Path: MustNewPointerFromString("/abc"), Value: 123},
+					ErrorMsg: errorMsg,
+				},
+			})))
+		})
+
+		It("requires path", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "add"}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Add operation [0]: Missing path within
+{
+  "Type": "add"
+}`))
+		})
+
+		It("requires value", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "add", Path: &path}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Add operation [0]: Missing value within
+{
+  "Type": "add",
+  "Path": "/abc"
+}`))
+		})
+
+		It("requires valid path", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "add", Path: &invalidPath, Value: &val}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Add operation [0]: Invalid path: Expected to start with '/' within
+{
+  "Type": "add",
+  "Path": "abc",
+  "Value": "<redacted>"
+}`))
+		})
+	})
+
+	Describe("default", func() {
+		It("allows error description", func() {
+			opDefs := []OpDefinition{{Type: "default", Path: &path, Value: &val, Error: &errorMsg}}
+
+			ops, err := NewOpsFromDefinitions(opDefs)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ops).To(Equal(Ops([]Op{
+				DescriptiveOp{
+					Op:       DefaultOp{Path: MustNewPointerFromString("/abc"), Value: 123},
+					ErrorMsg: errorMsg,
+				},
+			})))
+		})
+
+		It("requires path", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "default"}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Default operation [0]: Missing path within
+{
+  "Type": "default"
+}`))
+		})
+
+		It("requires value", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "default", Path: &path}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Default operation [0]: Missing value within
+{
+  "Type": "default",
+  "Path": "/abc"
+}`))
+		})
+
+		It("requires valid path", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "default", Path: &invalidPath, Value: &val}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Default operation [0]: Invalid path: Expected to start with '/' within
+{
+  "Type": "default",
+  "Path": "abc",
+  "Value": "<redacted>"
+}`))
+		})
+	})
+
 	Describe("remove", func() {
 		It("allows error description", func() {
 			opDefs := []OpDefinition{{Type: "remove", Path: &path, Error: &errorMsg}}
@@ -317,6 +431,265 @@ var _ = Describe("NewOpsFromDefinitions", func() {
   "Type": "qmove",
   "Path": "/abc",
   "From": "abc"
+}`))
+		})
+	})
+
+	Describe("merge", func() {
+		It("allows error description", func() {
+			opDefs := []OpDefinition{{Type: "merge", Path: &path, Value: &val, Error: &errorMsg}}
+
+			ops, err := NewOpsFromDefinitions(opDefs)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ops).To(Equal(Ops([]Op{
+				DescriptiveOp{
+					Op:       MergeOp{Path: MustNewPointerFromString("/abc"), Value: 123},
+					ErrorMsg: errorMsg,
+				},
+			})))
+		})
+
+		It("requires path", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "merge"}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Merge operation [0]: Missing path within
+{
+  "Type": "merge"
+}`))
+		})
+
+		It("requires value", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "merge", Path: &path}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Merge operation [0]: Missing value within
+{
+  "Type": "merge",
+  "Path": "/abc"
+}`))
+		})
+
+		It("requires valid path", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "merge", Path: &invalidPath, Value: &val}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Merge operation [0]: Invalid path: Expected to start with '/' within
+{
+  "Type": "merge",
+  "Path": "abc",
+  "Value": "<redacted>"
+}`))
+		})
+
+		It("allows array strategy", func() {
+			arrays := "key=name"
+
+			ops, err := NewOpsFromDefinitions([]OpDefinition{{Type: "merge", Path: &path, Value: &val, Arrays: &arrays}})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ops).To(Equal(Ops([]Op{
+				MergeOp{Path: MustNewPointerFromString("/abc"), Value: 123, Arrays: MergeArraysByKey("name")},
+			})))
+		})
+
+		It("requires valid array strategy", func() {
+			arrays := "other"
+
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "merge", Path: &path, Value: &val, Arrays: &arrays}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Merge operation [0]: Invalid arrays: Expected array strategy to be 'replace', 'append' or 'key=<key>' but found 'other' within
+{
+  "Type": "merge",
+  "Path": "/abc",
+  "Value": "<redacted>",
+  "Arrays": "other"
+}`))
+		})
+	})
+	Describe("append-unique", func() {
+		It("allows error description", func() {
+			opDefs := []OpDefinition{{Type: "append-unique", Path: &path, Value: &val, Error: &errorMsg}}
+
+			ops, err := NewOpsFromDefinitions(opDefs)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ops).To(Equal(Ops([]Op{
+				DescriptiveOp{
+					Op:       AppendUniqueOp{Path: MustNewPointerFromString("/abc"), Value: 123},
+					ErrorMsg: errorMsg,
+				},
+			})))
+		})
+
+		It("requires path", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "append-unique"}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`AppendUnique operation [0]: Missing path within
+{
+  "Type": "append-unique"
+}`))
+		})
+
+		It("requires value", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "append-unique", Path: &path}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`AppendUnique operation [0]: Missing value within
+{
+  "Type": "append-unique",
+  "Path": "/abc"
+}`))
+		})
+
+		It("requires valid path", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "append-unique", Path: &invalidPath, Value: &val}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`AppendUnique operation [0]: Invalid path: Expected to start with '/' within
+{
+  "Type": "append-unique",
+  "Path": "abc",
+  "Value": "<redacted>"
+}`))
+		})
+	})
+
+	Describe("remove-value", func() {
+		It("allows error description", func() {
+			opDefs := []OpDefinition{{Type: "remove-value", Path: &path, Value: &val, Error: &errorMsg}}
+
+			ops, err := NewOpsFromDefinitions(opDefs)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ops).To(Equal(Ops([]Op{
+				DescriptiveOp{
+					Op:       RemoveValueOp{Path: MustNewPointerFromString("/abc"), Value: 123},
+					ErrorMsg: errorMsg,
+				},
+			})))
+		})
+
+		It("requires path", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "remove-value"}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`RemoveValue operation [0]: Missing path within
+{
+  "Type": "remove-value"
+}`))
+		})
+
+		It("requires value", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "remove-value", Path: &path}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`RemoveValue operation [0]: Missing value within
+{
+  "Type": "remove-value",
+  "Path": "/abc"
+}`))
+		})
+
+		It("requires valid path", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "remove-value", Path: &invalidPath, Value: &val}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`RemoveValue operation [0]: Invalid path: Expected to start with '/' within
+{
+  "Type": "remove-value",
+  "Path": "abc",
+  "Value": "<redacted>"
+}`))
+		})
+	})
+
+	Describe("sort", func() {
+		It("requires path", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "sort"}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Sort operation [0]: Missing path within
+{
+  "Type": "sort"
+}`))
+		})
+
+		It("does not allow value", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "sort", Path: &path, Value: &val}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Sort operation [0]: Cannot specify value within
+{
+  "Type": "sort",
+  "Path": "/abc",
+  "Value": "<redacted>"
+}`))
+		})
+
+		It("requires valid path", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "sort", Path: &invalidPath}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Sort operation [0]: Invalid path: Expected to start with '/' within
+{
+  "Type": "sort",
+  "Path": "abc"
+}`))
+		})
+	})
+
+	Describe("dedupe", func() {
+		It("requires path", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "dedupe"}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Dedupe operation [0]: Missing path within
+{
+  "Type": "dedupe"
+}`))
+		})
+
+		It("does not allow value", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "dedupe", Path: &path, Value: &val}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Dedupe operation [0]: Cannot specify value within
+{
+  "Type": "dedupe",
+  "Path": "/abc",
+  "Value": "<redacted>"
+}`))
+		})
+
+		It("requires valid path", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "dedupe", Path: &invalidPath}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Dedupe operation [0]: Invalid path: Expected to start with '/' within
+{
+  "Type": "dedupe",
+  "Path": "abc"
+}`))
+		})
+	})
+
+	Describe("reorder", func() {
+		It("requires path", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "reorder", Index: &index}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Reorder operation [0]: Missing path within
+{
+  "Type": "reorder",
+  "Index": -1
+}`))
+		})
+
+		It("requires index", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "reorder", Path: &path}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Reorder operation [0]: Missing index within
+{
+  "Type": "reorder",
+  "Path": "/abc"
+}`))
+		})
+
+		It("requires valid path", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "reorder", Path: &invalidPath, Index: &index}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Reorder operation [0]: Invalid path: Expected to start with '/' within
+{
+  "Type": "reorder",
+  "Path": "abc",
+  "Index": -1
 }`))
 		})
 	})
@@ -391,4 +764,222 @@ var _ = Describe("NewOpDefinitionsFromOps", func() {
     }
 ]`))
 	})
+
+	It("supports 'add' operation serialized", func() {
+		opDefs, err := NewOpDefinitionsFromOps(Ops([]Op{
+			AddOp{Path: MustNewPointerFromString("/abc?/-"), Value: 123},
+		}))
+		Expect(err).ToNot(HaveOccurred())
+
+		bs, err := yaml.Marshal(opDefs)
+		Expect(err).ToNot(HaveOccurred())
+		Expect("\n" + string(bs)).To(Equal(`
+- type: add
+  path: /abc?/-
+  value: 123
+`))
+	})
+
+	It("supports 'default' operation serialized", func() {
+		opDefs, err := NewOpDefinitionsFromOps(Ops([]Op{
+			DefaultOp{Path: MustNewPointerFromString("/abc"), Value: 123},
+		}))
+		Expect(err).ToNot(HaveOccurred())
+
+		bs, err := yaml.Marshal(opDefs)
+		Expect(err).ToNot(HaveOccurred())
+		Expect("\n" + string(bs)).To(Equal(`
+- type: default
+  path: /abc
+  value: 123
+`))
+	})
+
+	It("supports 'append-unique' and 'remove-value' operations serialized", func() {
+		opDefs, err := NewOpDefinitionsFromOps(Ops([]Op{
+			AppendUniqueOp{Path: MustNewPointerFromString("/abc"), Value: 123},
+			AppendUniqueOp{Path: MustNewPointerFromString("/abc"), Value: map[interface{}]interface{}{"name": "a"}, Key: "name"},
+			RemoveValueOp{Path: MustNewPointerFromString("/abc"), Value: 123},
+		}))
+		Expect(err).ToNot(HaveOccurred())
+
+		bs, err := yaml.Marshal(opDefs)
+		Expect(err).ToNot(HaveOccurred())
+		Expect("\n" + string(bs)).To(Equal(`
+- type: append-unique
+  path: /abc
+  value: 123
+- type: append-unique
+  path: /abc
+  value:
+    name: a
+  key: name
+- type: remove-value
+  path: /abc
+  value: 123
+`))
+	})
+
+	It("supports 'sort', 'dedupe' and 'reorder' operations serialized", func() {
+		opDefs, err := NewOpDefinitionsFromOps(Ops([]Op{
+			SortOp{Path: MustNewPointerFromString("/abc")},
+			SortOp{Path: MustNewPointerFromString("/abc"), Key: "name"},
+			DedupeOp{Path: MustNewPointerFromString("/abc"), Key: "name"},
+			ReorderOp{Path: MustNewPointerFromString("/abc/name=a"), Index: 0},
+		}))
+		Expect(err).ToNot(HaveOccurred())
+
+		bs, err := yaml.Marshal(opDefs)
+		Expect(err).ToNot(HaveOccurred())
+		Expect("\n" + string(bs)).To(Equal(`
+- type: sort
+  path: /abc
+- type: sort
+  path: /abc
+  key: name
+- type: dedupe
+  path: /abc
+  key: name
+- type: reorder
+  path: /abc/name=a
+  index: 0
+`))
+	})
+
+	It("supports 'merge' operation serialized", func() {
+		opDefs, err := NewOpDefinitionsFromOps(Ops([]Op{
+			MergeOp{Path: MustNewPointerFromString("/abc"), Value: map[interface{}]interface{}{"a": 1}},
+			MergeOp{Path: MustNewPointerFromString("/abc"), Value: []interface{}{}, Arrays: MergeArraysAppend},
+		}))
+		Expect(err).ToNot(HaveOccurred())
+
+		bs, err := yaml.Marshal(opDefs)
+		Expect(err).ToNot(HaveOccurred())
+		Expect("\n" + string(bs)).To(Equal(`
+- type: merge
+  path: /abc
+  value:
+    a: 1
+- type: merge
+  path: /abc
+  value: []
+  arrays: append
+`))
+	})
+})
+
+// upcaseOp is a custom operation used to test operation type registration
+type upcaseOp struct {
+	Path Pointer
+}
+
+func (op upcaseOp) Apply(doc interface{}) (interface{}, error) {
+	val, err := FindOp{Path: op.Path}.Apply(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	str, ok := val.(string)
+	if !ok {
+		return nil, errors.New("Expected to find a string")
+	}
+
+	return ReplaceOp{Path: op.Path, Value: strings.ToUpper(str)}.Apply(doc)
+}
+
+func init() {
+	RegisterOpType("upcase", func(opDef OpDefinition) (Op, error) {
+		if opDef.Path == nil {
+			return nil, errors.New("Missing path")
+		}
+
+		ptr, err := NewPointerFromString(*opDef.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		return upcaseOp{Path: ptr}, nil
+	}, func(op Op) (OpDefinition, bool) {
+		typedOp, ok := op.(upcaseOp)
+		if !ok {
+			return OpDefinition{}, false
+		}
+
+		path := typedOp.Path.String()
+
+		return OpDefinition{Path: &path}, true
+	})
+}
+
+var _ = Describe("RegisterOpType", func() {
+	It("allows custom operations to be parsed and applied", func() {
+		var opDefs []OpDefinition
+
+		err := yaml.Unmarshal([]byte("- type: upcase\n  path: /name\n  error: custom-error"), &opDefs)
+		Expect(err).ToNot(HaveOccurred())
+
+		ops, err := NewOpsFromDefinitions(opDefs)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ops).To(Equal(Ops{
+			DescriptiveOp{Op: upcaseOp{Path: MustNewPointerFromString("/name")}, ErrorMsg: "custom-error"},
+		}))
+
+		res, err := ops.Apply(map[interface{}]interface{}{"name": "abc"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(map[interface{}]interface{}{"name": "ABC"}))
+	})
+
+	It("returns an error if custom operation cannot be parsed", func() {
+		_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "upcase"}})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal(`Operation [0] with type 'upcase': Missing path within
+{
+  "Type": "upcase"
+}`))
+	})
+
+	It("serializes custom operations", func() {
+		opDefs, err := NewOpDefinitionsFromOps(Ops{
+			RemoveOp{Path: MustNewPointerFromString("/abc")},
+			upcaseOp{Path: MustNewPointerFromString("/name")},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		bs, err := yaml.Marshal(opDefs)
+		Expect(err).ToNot(HaveOccurred())
+		Expect("\n" + string(bs)).To(Equal(`
+- type: remove
+  path: /abc
+- type: upcase
+  path: /name
+`))
+	})
+
+	It("returns an error if operation cannot be serialized", func() {
+		_, err := NewOpDefinitionsFromOps(Ops{FindOp{Path: MustNewPointerFromString("/abc")}})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Unknown operation [0] with type 'patch.FindOp'"))
+	})
+
+	It("panics if operation type is built-in or already registered", func() {
+		parse := func(OpDefinition) (Op, error) { return nil, nil }
+
+		panicMsg := func(f func()) (msg interface{}) {
+			defer func() { msg = recover() }()
+			f()
+			return nil
+		}
+
+		Expect(panicMsg(func() { RegisterOpType("replace", parse, nil) })).To(
+			Equal("Expected operation type 'replace' to not be built-in"))
+
+		Expect(panicMsg(func() { RegisterOpType("reorder", parse, nil) })).To(
+			Equal("Expected operation type 'reorder' to not be built-in"))
+
+		Expect(panicMsg(func() { RegisterOpType("upcase", parse, nil) })).To(
+			Equal("Expected operation type 'upcase' to be registered once"))
+
+		Expect(func() { RegisterOpType("", parse, nil) }).To(Panic())
+		Expect(func() { RegisterOpType("other", nil, nil) }).To(Panic())
+	})
 })
@@ -9,16 +9,61 @@ type Op interface {
 // Ensure basic operations implement Op
 var _ Op = Ops{}
 var _ Op = ReplaceOp{}
+var _ Op = AddOp{}
+var _ Op = DefaultOp{}
+var _ Op = AppendUniqueOp{}
+var _ Op = RemoveValueOp{}
+var _ Op = SortOp{}
+var _ Op = DedupeOp{}
+var _ Op = ReorderOp{}
 var _ Op = RemoveOp{}
 var _ Op = FindOp{}
+var _ Op = MergeOp{}
 var _ Op = DescriptiveOp{}
 var _ Op = ErrOp{}
 
+// Apply applies operations in order without modifying given document, hence it is left
+// untouched even if one of the operations fails. Only maps and arrays along modified paths
+// are copied (see ApplyCopyOnWrite), so the result shares the rest with given document.
+// Operations applied individually modify given document in place.
 func (ops Ops) Apply(doc interface{}) (interface{}, error) {
+	return ops.ApplyCopyOnWrite(doc)
+}
+
+// ApplyCollectingErrs applies operations without modifying given document like Apply but does not stop
+// at the first failing operation: failing operations are skipped (without leaving partial changes)
+// and the rest are applied. Resulting document is returned along with MultiOpErr if any operation failed.
+func (ops Ops) ApplyCollectingErrs(doc interface{}) (interface{}, error) {
+	return ops.applyCollectingErrs(doc, nil)
+}
+
+func (ops Ops) applyCollectingErrs(doc interface{}, m matchers) (interface{}, error) {
+	var errs []OpErr
+
+	for i, op := range ops {
+		// Each operation copies maps and arrays it modifies hence failing one leaves document as is
+		result, err := copyOnWrite{owned: map[uintptr]struct{}{}, matchers: m}.apply(op, doc)
+		if err != nil {
+			errs = append(errs, OpErr{Index: i, Op: op, Err: err})
+			continue
+		}
+
+		doc = result
+	}
+
+	if len(errs) > 0 {
+		return doc, MultiOpErr{errs}
+	}
+
+	return doc, nil
+}
+
+// apply applies operations modifying given document in place
+func (ops Ops) apply(doc interface{}, m matchers) (interface{}, error) {
 	var err error
 
 	for _, op := range ops {
-		doc, err = op.Apply(doc)
+		doc, err = applyOp(op, doc, m)
 		if err != nil {
 			return nil, err
 		}
@@ -26,3 +71,46 @@ func (ops Ops) Apply(doc interface{}) (interface{}, error) {
 
 	return doc, nil
 }
+
+// matchersOp is implemented by operations that are able to use matchers prepared
+// ahead of time (see Compile); their Apply prepares matchers whenever they are used
+type matchersOp interface {
+	Op
+	apply(doc interface{}, m matchers) (interface{}, error)
+}
+
+func applyOp(op Op, doc interface{}, m matchers) (interface{}, error) {
+	if typedOp, ok := op.(matchersOp); ok {
+		return typedOp.apply(doc, m)
+	}
+	return op.Apply(doc)
+}
+
+// copyDoc returns a copy of all maps and arrays within the document
+func copyDoc(doc interface{}) interface{} {
+	switch typedDoc := doc.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[interface{}]interface{}, len(typedDoc))
+		for k, v := range typedDoc {
+			result[k] = copyDoc(v)
+		}
+		return result
+
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(typedDoc))
+		for k, v := range typedDoc {
+			result[k] = copyDoc(v)
+		}
+		return result
+
+	case []interface{}:
+		result := make([]interface{}, len(typedDoc))
+		for i, v := range typedDoc {
+			result[i] = copyDoc(v)
+		}
+		return result
+
+	default:
+		return doc
+	}
+}
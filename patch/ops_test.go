@@ -37,4 +37,192 @@ var _ = Describe("Ops.Apply", func() {
 		Expect(err).To(HaveOccurred())
 		Expect(err.Error()).To(ContainSubstring("fake-err"))
 	})
+
+	It("leaves input untouched if any operation errors", func() {
+		doc := map[interface{}]interface{}{
+			"a":     map[interface{}]interface{}{"b": 1},
+			"items": []interface{}{1, 2},
+		}
+
+		ops := Ops([]Op{
+			ReplaceOp{Path: MustNewPointerFromString("/a/b"), Value: 2},
+			ReplaceOp{Path: MustNewPointerFromString("/a/c?/d"), Value: 3},
+			RemoveOp{Path: MustNewPointerFromString("/items/0")},
+			SortOp{Path: MustNewPointerFromString("/items")},
+			ReplaceOp{Path: MustNewPointerFromString("/a/b/c"), Value: 3},
+		})
+
+		_, err := ops.Apply(doc)
+		Expect(err).To(HaveOccurred())
+
+		Expect(doc).To(Equal(map[interface{}]interface{}{
+			"a":     map[interface{}]interface{}{"b": 1},
+			"items": []interface{}{1, 2},
+		}))
+	})
+
+	It("leaves input untouched if all operations succeed", func() {
+		doc := map[string]interface{}{
+			"a": []interface{}{map[string]interface{}{"b": float64(1)}},
+		}
+
+		res, err := Ops([]Op{
+			ReplaceOp{Path: MustNewPointerFromString("/a/0/b"), Value: 2},
+			Ops{ReplaceOp{Path: MustNewPointerFromString("/a/-"), Value: 3}},
+		}).Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(map[string]interface{}{
+			"a": []interface{}{map[string]interface{}{"b": 2}, 3},
+		}))
+		Expect(doc).To(Equal(map[string]interface{}{
+			"a": []interface{}{map[string]interface{}{"b": float64(1)}},
+		}))
+	})
+
+	It("leaves input untouched when nested operations are described", func() {
+		doc := map[interface{}]interface{}{
+			"a": map[interface{}]interface{}{"b": 1},
+			"c": []interface{}{1},
+		}
+
+		res, err := Ops([]Op{
+			DescriptiveOp{Op: Ops{ReplaceOp{Path: MustNewPointerFromString("/a/b"), Value: 2}}, ErrorMsg: "msg"},
+		}).Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(map[interface{}]interface{}{
+			"a": map[interface{}]interface{}{"b": 2},
+			"c": []interface{}{1},
+		}))
+		Expect(doc).To(Equal(map[interface{}]interface{}{
+			"a": map[interface{}]interface{}{"b": 1},
+			"c": []interface{}{1},
+		}))
+	})
+
+	It("only copies maps and arrays along modified paths", func() {
+		unmodified := []interface{}{1}
+		doc := map[interface{}]interface{}{
+			"a": map[interface{}]interface{}{"b": 1},
+			"c": unmodified,
+		}
+
+		res, err := Ops([]Op{
+			ReplaceOp{Path: MustNewPointerFromString("/a/b"), Value: 2},
+		}).Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		resC := res.(map[interface{}]interface{})["c"].([]interface{})
+		Expect(&resC[0]).To(BeIdenticalTo(&unmodified[0]))
+		Expect(doc["a"]).To(Equal(map[interface{}]interface{}{"b": 1}))
+	})
+})
+
+var _ = Describe("Ops.ApplyCollectingErrs", func() {
+	var doc interface{}
+
+	BeforeEach(func() {
+		doc = map[interface{}]interface{}{
+			"name":  "dep",
+			"items": []interface{}{map[interface{}]interface{}{"name": "a"}, "b"},
+		}
+	})
+
+	It("applies all operations if none of them errors", func() {
+		res, err := Ops{
+			ReplaceOp{Path: MustNewPointerFromString("/name"), Value: "dep2"},
+			RemoveOp{Path: MustNewPointerFromString("/items/1")},
+		}.ApplyCollectingErrs(doc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(map[interface{}]interface{}{
+			"name":  "dep2",
+			"items": []interface{}{map[interface{}]interface{}{"name": "a"}},
+		}))
+	})
+
+	It("skips failing operations and returns errors of all of them along with resulting document", func() {
+		ops := Ops{
+			ReplaceOp{Path: MustNewPointerFromString("/missing/key"), Value: 1},
+			ReplaceOp{Path: MustNewPointerFromString("/name"), Value: "dep2"},
+			DescriptiveOp{Op: RemoveOp{Path: MustNewPointerFromString("/items/5")}, ErrorMsg: "custom"},
+			ReplaceOp{Path: MustNewPointerFromString("/items/-"), Value: "c"},
+			ErrOp{Err: errors.New("error")},
+		}
+
+		res, err := ops.ApplyCollectingErrs(doc)
+		Expect(err).To(HaveOccurred())
+
+		Expect(res).To(Equal(map[interface{}]interface{}{
+			"name":  "dep2",
+			"items": []interface{}{map[interface{}]interface{}{"name": "a"}, "b", "c"},
+		}))
+
+		multiErr, ok := err.(MultiOpErr)
+		Expect(ok).To(BeTrue())
+		Expect(multiErr.Errs).To(HaveLen(3))
+
+		Expect(multiErr.Errs[0].Index).To(Equal(0))
+		Expect(multiErr.Errs[0].Op).To(Equal(ops[0]))
+		Expect(multiErr.Errs[0].Err).To(BeAssignableToTypeOf(OpMissingMapKeyErr{}))
+
+		var missingIdxErr OpMissingIndexErr
+
+		Expect(multiErr.Errs[1].Index).To(Equal(2))
+		Expect(errors.As(multiErr.Errs[1], &missingIdxErr)).To(BeTrue())
+		Expect(missingIdxErr.Idx).To(Equal(5))
+
+		Expect(multiErr.Errs[2].Index).To(Equal(4))
+		Expect(multiErr.Errs[2].Err).To(Equal(errors.New("error")))
+
+		Expect(err.Error()).To(Equal(`Expected all operations to succeed but 3 failed:
+
+Operation [0]: Expected to find a map key 'missing' for path '/missing' (found map keys: 'items', 'name') within
+{
+  "Type": "replace",
+  "Path": "/missing/key",
+  "Value": "<redacted>"
+}
+
+Operation [2]: Error 'custom': Expected to find array index '5' but found array of length '2' for path '/items/5' within
+{
+  "Type": "remove",
+  "Path": "/items/5",
+  "Error": "custom"
+}
+
+Operation [4]: error within
+<patch.ErrOp>`))
+	})
+
+	It("does not leave partial changes of failing operations", func() {
+		res, err := Ops{
+			Ops{
+				ReplaceOp{Path: MustNewPointerFromString("/name"), Value: "dep2"},
+				RemoveOp{Path: MustNewPointerFromString("/items/0/name")},
+				RemoveOp{Path: MustNewPointerFromString("/missing")},
+			},
+			ReplaceOp{Path: MustNewPointerFromString("/items/*/name"), Value: "c"},
+		}.ApplyCollectingErrs(doc)
+		Expect(err).To(HaveOccurred())
+		Expect(err.(MultiOpErr).Errs).To(HaveLen(2))
+
+		Expect(res).To(Equal(map[interface{}]interface{}{
+			"name":  "dep",
+			"items": []interface{}{map[interface{}]interface{}{"name": "a"}, "b"},
+		}))
+	})
+
+	It("leaves input untouched", func() {
+		_, err := Ops{
+			RemoveOp{Path: MustNewPointerFromString("/items/0/name")},
+			RemoveOp{Path: MustNewPointerFromString("/missing")},
+		}.ApplyCollectingErrs(doc)
+		Expect(err).To(HaveOccurred())
+
+		Expect(doc).To(Equal(map[interface{}]interface{}{
+			"name":  "dep",
+			"items": []interface{}{map[interface{}]interface{}{"name": "a"}, "b"},
+		}))
+	})
 })
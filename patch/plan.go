@@ -0,0 +1,190 @@
+package patch
+
+import (
+	"fmt"
+)
+
+// Plan is a list of operations which paths were validated and which matching conditions
+// were prepared ahead of time, hence it is cheaper to apply to many documents.
+// It could be used concurrently.
+type Plan struct {
+	ops      Ops
+	matchers matchers
+}
+
+var _ Op = Plan{}
+
+// Compile validates paths of operations without a document (e.g. that no modifiers follow
+// 'before' or 'after' modifiers, that after last index token is the last one
+// and that regular expressions compile), so that such errors are reported
+// before operations are applied to any document. Conditions of matching index tokens
+// are prepared once (regular expressions are compiled and values are parsed as YAML scalars)
+// instead of every time operations are applied. Operations of unknown types are included as is.
+func Compile(ops Ops) (Plan, error) {
+	m := matchers{}
+
+	for i, op := range ops {
+		err := compileOp(op, m)
+		if err != nil {
+			return Plan{}, fmt.Errorf("Operation [%d]: %s", i, err)
+		}
+	}
+
+	return Plan{append(Ops{}, ops...), m}, nil
+}
+
+// Apply applies operations the same way as Ops.Apply
+func (p Plan) Apply(doc interface{}) (interface{}, error) {
+	return p.ApplyCopyOnWrite(doc)
+}
+
+// ApplyCopyOnWrite applies operations the same way as Ops.ApplyCopyOnWrite
+func (p Plan) ApplyCopyOnWrite(doc interface{}) (interface{}, error) {
+	return copyOnWrite{owned: map[uintptr]struct{}{}, matchers: p.matchers}.applyOps(p.ops, doc)
+}
+
+// ApplyCollectingErrs applies operations the same way as Ops.ApplyCollectingErrs
+func (p Plan) ApplyCollectingErrs(doc interface{}) (interface{}, error) {
+	return p.ops.applyCollectingErrs(doc, p.matchers)
+}
+
+func (p Plan) apply(doc interface{}, _ matchers) (interface{}, error) {
+	return p.ops.apply(doc, p.matchers)
+}
+
+func compileOp(op Op, m matchers) error {
+	switch typedOp := op.(type) {
+	case Ops:
+		for _, nestedOp := range typedOp {
+			err := compileOp(nestedOp, m)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case DescriptiveOp:
+		err := compileOp(typedOp.Op, m)
+		if err != nil {
+			return fmt.Errorf("Error '%s': %s", typedOp.ErrorMsg, err)
+		}
+		return nil
+
+	case MergeOp:
+		err := typedOp.Arrays.validate()
+		if err != nil {
+			return err
+		}
+		return compilePathOp(typedOp, m)
+
+	case QCopyOp:
+		err := compilePointer(typedOp.From, false, m)
+		if err != nil {
+			return err
+		}
+		return compilePointer(typedOp.Path, true, m)
+
+	case QMoveOp:
+		err := compilePointer(typedOp.From, false, m)
+		if err != nil {
+			return err
+		}
+		return compilePointer(typedOp.Path, true, m)
+
+	case FindOp:
+		return compilePointer(typedOp.Path, false, m)
+
+	case pathOp:
+		return compilePathOp(typedOp, m)
+
+	default:
+		return nil
+	}
+}
+
+func compilePathOp(op pathOp, m matchers) error {
+	var insertion bool
+
+	// Only operations that set values are able to refer to a position to insert at
+	switch op.(type) {
+	case ReplaceOp, AddOp, DefaultOp, MergeOp:
+		insertion = true
+	}
+
+	return compilePointer(op.path(), insertion, m)
+}
+
+// compilePointer checks pointer the same way as walker does for any document
+// and prepares conditions of its matching index tokens; insertion indicates
+// whether last token is allowed to refer to a position to insert at
+func compilePointer(ptr Pointer, insertion bool, m matchers) error {
+	tokens := ptr.Tokens()
+
+	for i, token := range tokens {
+		isLast := i == len(tokens)-1
+
+		switch typedToken := token.(type) {
+		case RootToken:
+			if i > 0 {
+				return fmt.Errorf("Expected to find root token only at the beginning of path '%s'", ptr)
+			}
+
+		case IndexToken:
+			err := compileModifiers(typedToken.Modifiers, ptr, isLast && insertion)
+			if err != nil {
+				return err
+			}
+
+		case MatchingIndexToken:
+			err := compileModifiers(typedToken.Modifiers, ptr, isLast && insertion)
+			if err != nil {
+				return err
+			}
+
+			for _, cond := range typedToken.Conditions() {
+				err := m.prepare(cond)
+				if err != nil {
+					return err
+				}
+			}
+
+		case AfterLastIndexToken:
+			if !isLast {
+				return fmt.Errorf("Expected after last index token to be last in path '%s'", ptr)
+			}
+			if !insertion {
+				return OpUnexpectedTokenErr{token, ptr}
+			}
+		}
+	}
+
+	return nil
+}
+
+// compileModifiers checks modifiers the same way as ArrayIndex and ArrayInsertion do
+func compileModifiers(modifiers []Modifier, ptr Pointer, insertion bool) error {
+	var found Modifier
+
+	for _, modifier := range modifiers {
+		switch found.(type) {
+		case BeforeModifier:
+			return fmt.Errorf("Expected to not find any modifiers after 'before' modifier, but found modifier '%T' for path '%s'", modifier, ptr)
+		case AfterModifier:
+			return fmt.Errorf("Expected to not find any modifiers after 'after' modifier, but found modifier '%T' for path '%s'", modifier, ptr)
+		}
+
+		switch modifier.(type) {
+		case PrevModifier, NextModifier:
+		case BeforeModifier, AfterModifier:
+			if !insertion {
+				errMsg := "Expected to find one of the following modifiers: 'prev', 'next', but found modifier '%T' for path '%s'"
+				return fmt.Errorf(errMsg, modifier, ptr)
+			}
+			found = modifier
+		default:
+			return fmt.Errorf("Expected to find one of the following modifiers: 'prev', 'next', 'before', 'after', but found modifier '%T' for path '%s'", modifier, ptr)
+		}
+	}
+
+	return nil
+}
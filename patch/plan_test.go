@@ -0,0 +1,176 @@
+package patch_test
+
+import (
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v2"
+
+	. "github.com/SUSE/go-patch/patch"
+)
+
+var _ = Describe("Compile", func() {
+	var doc interface{}
+
+	BeforeEach(func() {
+		err := yaml.Unmarshal([]byte(`
+name: dep
+instance_groups:
+- name: web-1
+  instances: 1
+  jobs: [{name: nginx, properties: {port: 80}}]
+- name: web-2
+  instances: 2
+  jobs: [{name: nginx, properties: {port: 8080}}]
+- name: db
+  jobs: [{name: pg}]
+`), &doc)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("returns plan that produces the same results as operations", func() {
+		ops := parseOps(`
+- type: replace
+  path: /instance_groups/name~=web-.*:all/instances
+  value: 3
+- type: replace
+  path: /instance_groups/name=web-2/jobs/properties.port=8080/properties/tls?
+  value: true
+- type: replace
+  path: /instance_groups/name=db/jobs/name=pg:before
+  value: {name: bouncer}
+- type: remove
+  path: /instance_groups/name=web-1/jobs/name=nginx
+- type: merge
+  path: /instance_groups/name*=db
+  value: {azs: [z1]}
+- type: qcopy
+  from: /instance_groups/name=web-2/jobs/0
+  path: /instance_groups/name=web-1/jobs/-
+- type: default
+  path: /instance_groups/*/instances
+  value: 1
+- type: test
+  path: /instance_groups/name=db/lifecycle
+  absent: true
+  error: db is expected to be a service
+- type: upcase
+  path: /name
+`)
+
+		plan, err := Compile(Ops{ops[:3], ops[3:]})
+		Expect(err).ToNot(HaveOccurred())
+
+		expected, err := ops.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		for i := 0; i < 2; i++ {
+			res, err := plan.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(expected))
+
+			res, err = plan.ApplyCopyOnWrite(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(expected))
+
+			res, err = plan.ApplyCollectingErrs(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(expected))
+
+			res, err = Ops{plan}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(expected))
+		}
+
+		// Applied individually plans modify given document in place like other operations
+		res, err := DescriptiveOp{Op: plan, ErrorMsg: "msg"}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(expected))
+	})
+
+	It("returns the same errors as operations when applied", func() {
+		ops := parseOps(`
+- type: replace
+  path: /instance_groups/name=web-3/instances
+  value: 3
+  error: web-3 is expected
+`)
+
+		_, expectedErr := ops.Apply(doc)
+		Expect(expectedErr).To(HaveOccurred())
+
+		plan, err := Compile(ops)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = plan.Apply(doc)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal(expectedErr.Error()))
+	})
+
+	It("could be applied concurrently", func() {
+		plan, err := Compile(parseOps(`
+- type: replace
+  path: /instance_groups/name~=web-[12]:all/jobs/properties.port=80?/properties/port
+  value: 443
+`))
+		Expect(err).ToNot(HaveOccurred())
+
+		var wg sync.WaitGroup
+
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+
+				res, err := plan.Apply(doc)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(FindOp{Path: MustNewPointerFromString("/instance_groups/0/jobs/0/properties/port")}.Apply(res)).To(Equal(443))
+			}()
+		}
+
+		wg.Wait()
+	})
+
+	It("returns an error if paths could not be used regardless of the document", func() {
+		errs := map[string]Op{
+			"Operation [0]: Expected to not find any modifiers after 'before' modifier, but found modifier 'patch.NextModifier' for path '/a/0:before:next'": ReplaceOp{Path: MustNewPointerFromString("/a/0:before:next")},
+
+			"Operation [0]: Expected to not find any modifiers after 'after' modifier, but found modifier 'patch.PrevModifier' for path '/a/k=v:after:prev'": AddOp{Path: MustNewPointerFromString("/a/k=v:after:prev")},
+
+			"Operation [0]: Expected to find one of the following modifiers: 'prev', 'next', but found modifier 'patch.BeforeModifier' for path '/a/0:before/b'": ReplaceOp{Path: MustNewPointerFromString("/a/0:before/b")},
+
+			"Operation [0]: Expected to find one of the following modifiers: 'prev', 'next', but found modifier 'patch.AfterModifier' for path '/a/0:after'": RemoveOp{Path: MustNewPointerFromString("/a/0:after")},
+
+			"Operation [0]: Expected after last index token to be last in path '/-/a'": ReplaceOp{Path: NewPointer([]Token{RootToken{}, AfterLastIndexToken{}, KeyToken{Key: "a"}})},
+
+			"Operation [0]: Expected to not find token 'patch.AfterLastIndexToken' at path '/a/-'": QMoveOp{From: MustNewPointerFromString("/a/-"), Path: MustNewPointerFromString("/b")},
+
+			"Operation [0]: Expected to find root token only at the beginning of path '/a/'": FindOp{Path: NewPointer([]Token{RootToken{}, KeyToken{Key: "a"}, RootToken{}})},
+
+			"Operation [0]: Expected to find valid regular expression for key 'name': error parsing regexp: missing closing ): `(`": SortOp{Path: NewPointer([]Token{RootToken{}, MatchingIndexToken{Key: "name", Value: "(", Operator: MatchingRegexp}})},
+
+			"Operation [0]: Expected array strategy to be 'replace', 'append' or 'key=<key>' but found 'prepend'": MergeOp{Path: MustNewPointerFromString("/a"), Arrays: "prepend"},
+		}
+
+		for errMsg, op := range errs {
+			_, err := Compile(Ops{op})
+			Expect(err).To(HaveOccurred(), errMsg)
+			Expect(err.Error()).To(Equal(errMsg))
+		}
+	})
+
+	It("reports index of the top level operation and error messages of descriptive operations", func() {
+		_, err := Compile(Ops{
+			FindOp{Path: MustNewPointerFromString("/a")},
+			Ops{
+				FindOp{Path: MustNewPointerFromString("/a")},
+				DescriptiveOp{Op: RemoveOp{Path: MustNewPointerFromString("/a/-")}, ErrorMsg: "custom"},
+			},
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Operation [1]: Error 'custom': Expected to not find token 'patch.AfterLastIndexToken' at path '/a/-'"))
+	})
+})
@@ -7,8 +7,13 @@ import (
 )
 
 var (
-	rfc6901Decoder = strings.NewReplacer("~0", "~", "~1", "/", "~7", ":")
-	rfc6901Encoder = strings.NewReplacer("~", "~0", "/", "~1", ":", "~7")
+	rfc6901Decoder = strings.NewReplacer("~0", "~", "~1", "/", "~2", "?", "~4", ",", "~7", ":", "~8", "*")
+	rfc6901Encoder = strings.NewReplacer("~", "~0", "/", "~1", "?", "~2", ":", "~7")
+
+	// Commas separate conditions within matching index tokens
+	// and '*' before '=' denotes glob condition
+	matchingEncoder    = strings.NewReplacer("~", "~0", "/", "~1", "?", "~2", ",", "~4", ":", "~7")
+	matchingKeyEncoder = strings.NewReplacer("~", "~0", "/", "~1", "?", "~2", ",", "~4", ":", "~7", "*", "~8")
 )
 
 // More or less based on https://tools.ietf.org/html/rfc6901
@@ -45,12 +50,17 @@ func NewPointerFromString(str string) (Pointer, error) {
 		isLast := i == len(tokenStrs)-1
 
 		var modifiers []Modifier
+		var all, optionalMod bool
 		tokPieces := strings.Split(tok, ":")
 
 		if len(tokPieces) > 1 {
 			tok = tokPieces[0]
 			for _, p := range tokPieces[1:] {
 				switch p {
+				case "all":
+					all = true
+				case "optional":
+					optionalMod = true
 				case "prev":
 					modifiers = append(modifiers, PrevModifier{})
 				case "next":
@@ -60,11 +70,32 @@ func NewPointerFromString(str string) (Pointer, error) {
 				case "after":
 					modifiers = append(modifiers, AfterModifier{})
 				default:
-					return Pointer{}, fmt.Errorf("Expected to find one of the following modifiers: 'prev', 'next', 'before', or 'after' but found '%s'", tokPieces[1])
+					return Pointer{}, fmt.Errorf("Expected to find one of the following modifiers: 'prev', 'next', 'before', 'after', 'all' or 'optional' but found '%s'", p)
 				}
 			}
 		}
 
+		if all && !strings.Contains(tok, "=") {
+			return Pointer{}, fmt.Errorf("Expected to find 'all' modifier only with matching index token")
+		}
+
+		if optionalMod && !strings.Contains(tok, "=") {
+			return Pointer{}, fmt.Errorf("Expected to find 'optional' modifier only with matching index token")
+		}
+
+		// parse as wildcard (literal '*' key is escaped as '~8')
+		if strings.TrimSuffix(tok, "?") == "*" {
+			if len(modifiers) > 0 {
+				return Pointer{}, fmt.Errorf("Expected not to find any modifiers with wildcard token")
+			}
+			if strings.HasSuffix(tok, "?") {
+				optional = true
+			}
+			tokens = append(tokens, WildcardToken{Optional: optional})
+			continue
+		}
+
+		rawTok := tok
 		tok = rfc6901Decoder.Replace(tok)
 
 		// parse as after last index
@@ -83,18 +114,46 @@ func NewPointerFromString(str string) (Pointer, error) {
 			continue
 		}
 
-		if strings.HasSuffix(tok, "?") {
+		// literal '?' is escaped as '~2'
+		optionalSuffix := strings.HasSuffix(rawTok, "?")
+		if optionalSuffix {
 			optional = true
+			rawTok = strings.TrimSuffix(rawTok, "?")
 		}
 
-		// parse name=val
-		kv := strings.SplitN(tok, "=", 2)
-		if len(kv) == 2 {
+		// parse name=val, name~=regexp, name*=glob or name=val,name2=val2
+		if strings.Contains(tok, "=") {
+			conds, err := newMatchingConditionsFromString(rawTok)
+			if err != nil {
+				return Pointer{}, err
+			}
+
+			// trailing '?' could be meant as part of regexp or glob
+			lastCond := conds[len(conds)-1]
+			if optionalSuffix && lastCond.Operator != MatchingEqual {
+				errMsg := "Expected regexp or glob condition value '%s?' to not end with '?' (use '~2' for literal '?' or 'optional' modifier)"
+				return Pointer{}, fmt.Errorf(errMsg, lastCond.Value)
+			}
+
+			if optionalSuffix || optionalMod {
+				// modifiers are relative to the matching item which may not exist
+				if len(modifiers) > 0 {
+					return Pointer{}, fmt.Errorf("Expected not to find any modifiers with optional matching index token")
+				}
+				optional = true
+			}
+
 			token := MatchingIndexToken{
-				Key:       kv[0],
-				Value:     strings.TrimSuffix(kv[1], "?"),
+				Key:       conds[0].Key,
+				Value:     conds[0].Value,
+				Operator:  conds[0].Operator,
 				Optional:  optional,
 				Modifiers: modifiers,
+				All:       all,
+			}
+
+			if len(conds) > 1 {
+				token.And = conds[1:]
 			}
 
 			tokens = append(tokens, token)
@@ -107,7 +166,7 @@ func NewPointerFromString(str string) (Pointer, error) {
 
 		// it's a map key
 		token := KeyToken{
-			Key:      strings.TrimSuffix(tok, "?"),
+			Key:      rfc6901Decoder.Replace(rawTok),
 			Optional: optional,
 		}
 
@@ -117,6 +176,51 @@ func NewPointerFromString(str string) (Pointer, error) {
 	return Pointer{tokens}, nil
 }
 
+// newMatchingConditionsFromString splits (still encoded) token into conditions.
+// Comma only starts a new condition if both sides look like conditions,
+// so that previously valid values such as 'a,b' keep their meaning.
+// Unescaped '~' or '*' right before '=' selects regexp or glob operator.
+func newMatchingConditionsFromString(str string) ([]MatchingCondition, error) {
+	var pieces []string
+
+	for _, piece := range strings.Split(str, ",") {
+		if len(pieces) > 0 && (!strings.Contains(piece, "=") || !strings.Contains(pieces[len(pieces)-1], "=")) {
+			pieces[len(pieces)-1] += "," + piece
+		} else {
+			pieces = append(pieces, piece)
+		}
+	}
+
+	var conds []MatchingCondition
+
+	for _, piece := range pieces {
+		kv := strings.SplitN(piece, "=", 2)
+		key, op := kv[0], MatchingEqual
+
+		switch {
+		case strings.HasSuffix(key, "~"):
+			key, op = strings.TrimSuffix(key, "~"), MatchingRegexp
+		case strings.HasSuffix(key, "*"):
+			key, op = strings.TrimSuffix(key, "*"), MatchingGlob
+		}
+
+		cond := MatchingCondition{
+			Key:      rfc6901Decoder.Replace(key),
+			Value:    rfc6901Decoder.Replace(kv[1]),
+			Operator: op,
+		}
+
+		_, err := cond.pattern()
+		if err != nil {
+			return nil, err
+		}
+
+		conds = append(conds, cond)
+	}
+
+	return conds, nil
+}
+
 func NewPointer(tokens []Token) Pointer {
 	if len(tokens) == 0 {
 		panic("Expected at least one token")
@@ -151,20 +255,41 @@ func (p Pointer) String() string {
 			strs = append(strs, "-")
 
 		case MatchingIndexToken:
-			key := rfc6901Encoder.Replace(typedToken.Key)
-			val := rfc6901Encoder.Replace(typedToken.Value)
+			var conds []string
+
+			for _, cond := range typedToken.Conditions() {
+				op := string(cond.Operator)
+				if cond.Operator == MatchingEqual {
+					op = "="
+				}
+
+				conds = append(conds, matchingKeyEncoder.Replace(cond.Key)+op+matchingEncoder.Replace(cond.Value))
+			}
+
+			str := strings.Join(conds, ",")
 
 			if typedToken.Optional {
 				if !optional {
-					val += "?"
+					if conds := typedToken.Conditions(); conds[len(conds)-1].Operator == MatchingEqual {
+						str += "?"
+					} else {
+						str += ":optional"
+					}
 					optional = true
 				}
 			}
 
-			strs = append(strs, fmt.Sprintf("%s=%s%s", key, val, p.modifiersString(typedToken.Modifiers)))
+			if typedToken.All {
+				str += ":all"
+			}
+
+			strs = append(strs, str+p.modifiersString(typedToken.Modifiers))
 
 		case KeyToken:
 			str := rfc6901Encoder.Replace(typedToken.Key)
+			if str == "*" {
+				str = "~8"
+			}
 
 			if typedToken.Optional { // /key?/key2/key3
 				if !optional {
@@ -175,6 +300,18 @@ func (p Pointer) String() string {
 
 			strs = append(strs, str)
 
+		case WildcardToken:
+			str := "*"
+
+			if typedToken.Optional {
+				if !optional {
+					str += "?"
+					optional = true
+				}
+			}
+
+			strs = append(strs, str)
+
 		default:
 			panic(fmt.Sprintf("Unknown token type '%T'", typedToken))
 		}
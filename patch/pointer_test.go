@@ -82,6 +82,53 @@ var testCases = []PointerTestCase{
 		MatchingIndexToken{Key: "name", Value: "val", Modifiers: []Modifier{AfterModifier{}}},
 	}},
 
+	// Wildcard
+	{"/*", []Token{RootToken{}, WildcardToken{}}},
+	{"/key/*/key2", []Token{RootToken{}, KeyToken{Key: "key"}, WildcardToken{}, KeyToken{Key: "key2"}}},
+	{"/*?/key", []Token{RootToken{}, WildcardToken{Optional: true}, KeyToken{Key: "key", Optional: true}}},
+	{"/key?/*", []Token{RootToken{}, KeyToken{Key: "key", Optional: true}, WildcardToken{Optional: true}}},
+	{"/~8", []Token{RootToken{}, KeyToken{Key: "*"}}},
+	{"/a*", []Token{RootToken{}, KeyToken{Key: "a*"}}},
+
+	{"/name=val,name2=val2", []Token{
+		RootToken{},
+		MatchingIndexToken{Key: "name", Value: "val", And: []MatchingCondition{{Key: "name2", Value: "val2"}}},
+	}},
+	{"/name=val,name2=val2?:all", []Token{
+		RootToken{},
+		MatchingIndexToken{Key: "name", Value: "val", Optional: true, All: true, And: []MatchingCondition{{Key: "name2", Value: "val2"}}},
+	}},
+	{"/props.port=80", []Token{RootToken{}, MatchingIndexToken{Key: "props.port", Value: "80"}}},
+	{"/name=a~4b", []Token{RootToken{}, MatchingIndexToken{Key: "name", Value: "a,b"}}},
+	{"/name=a~4b=c", []Token{RootToken{}, MatchingIndexToken{Key: "name", Value: "a,b=c"}}},
+	{"/name=val:all", []Token{RootToken{}, MatchingIndexToken{Key: "name", Value: "val", All: true}}},
+	{"/name=val?:all", []Token{
+		RootToken{},
+		MatchingIndexToken{Key: "name", Value: "val", Optional: true, All: true},
+	}},
+	{"/key?/name=val:after", []Token{
+		RootToken{},
+		KeyToken{Key: "key", Optional: true},
+		MatchingIndexToken{Key: "name", Value: "val", Optional: true, Modifiers: []Modifier{AfterModifier{}}},
+	}},
+
+	{"/name~=^a.*$", []Token{RootToken{}, MatchingIndexToken{Key: "name", Value: "^a.*$", Operator: MatchingRegexp}}},
+	{"/name~=^a,env=b?", []Token{
+		RootToken{},
+		MatchingIndexToken{Key: "name", Value: "^a", Operator: MatchingRegexp, Optional: true, And: []MatchingCondition{{Key: "env", Value: "b"}}},
+	}},
+	{"/name*=a-*:optional:all", []Token{
+		RootToken{},
+		MatchingIndexToken{Key: "name", Value: "a-*", Operator: MatchingGlob, Optional: true, All: true},
+	}},
+	{"/name=a,release~=^r", []Token{
+		RootToken{},
+		MatchingIndexToken{Key: "name", Value: "a", And: []MatchingCondition{{Key: "release", Value: "^r", Operator: MatchingRegexp}}},
+	}},
+	{"/name~=^a~1b~2$", []Token{RootToken{}, MatchingIndexToken{Key: "name", Value: "^a/b?$", Operator: MatchingRegexp}}},
+	{"/name~0=val", []Token{RootToken{}, MatchingIndexToken{Key: "name~", Value: "val"}}},
+	{"/name~8=val", []Token{RootToken{}, MatchingIndexToken{Key: "name*", Value: "val"}}},
+
 	// Optionality
 	{"/key?/name=val", []Token{
 		RootToken{},
@@ -94,12 +141,15 @@ var testCases = []PointerTestCase{
 		KeyToken{Key: "key", Optional: true},
 	}},
 
-	// Escaping (todo support ~2 for '?'; ~3 for '=')
+	// Escaping (todo support ~3 for '=')
 	{"/m~0n", []Token{RootToken{}, KeyToken{Key: "m~n"}}},
 	{"/a~01b", []Token{RootToken{}, KeyToken{Key: "a~1b"}}},
 	{"/a~1b", []Token{RootToken{}, KeyToken{Key: "a/b"}}},
 	{"/name~0n=val~0n", []Token{RootToken{}, MatchingIndexToken{Key: "name~n", Value: "val~n"}}},
 	{"/m~7n", []Token{RootToken{}, KeyToken{Key: "m:n"}}},
+	{"/m~2", []Token{RootToken{}, KeyToken{Key: "m?"}}},
+	{"/m~2?", []Token{RootToken{}, KeyToken{Key: "m?", Optional: true}}},
+	{"/name=val~2", []Token{RootToken{}, MatchingIndexToken{Key: "name", Value: "val?"}}},
 
 	// Special chars
 	{"/c%d", []Token{RootToken{}, KeyToken{Key: "c%d"}}},
@@ -133,7 +183,11 @@ var _ = Describe("NewPointerFromString", func() {
 	It("returns error if string includes unknown modifiers", func() {
 		_, err := NewPointerFromString("/abc:unknown")
 		Expect(err).To(HaveOccurred())
-		Expect(err.Error()).To(Equal("Expected to find one of the following modifiers: 'prev', 'next', 'before', or 'after' but found 'unknown'"))
+		Expect(err.Error()).To(Equal("Expected to find one of the following modifiers: 'prev', 'next', 'before', 'after', 'all' or 'optional' but found 'unknown'"))
+
+		_, err = NewPointerFromString("/items/name=a:all:bogus")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find one of the following modifiers: 'prev', 'next', 'before', 'after', 'all' or 'optional' but found 'bogus'"))
 	})
 
 	It("returns error if string has modifiers in after-last-index-token", func() {
@@ -142,6 +196,57 @@ var _ = Describe("NewPointerFromString", func() {
 		Expect(err.Error()).To(Equal("Expected not to find any modifiers with after last index token"))
 	})
 
+	It("returns error if string has modifiers in wildcard token", func() {
+		_, err := NewPointerFromString("/*:prev")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected not to find any modifiers with wildcard token"))
+	})
+
+	It("returns error if string has all modifier in non-matching token", func() {
+		_, err := NewPointerFromString("/0:all")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find 'all' modifier only with matching index token"))
+
+		_, err = NewPointerFromString("/*:all")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find 'all' modifier only with matching index token"))
+	})
+
+	It("returns error if string has modifiers in optional matching index token", func() {
+		_, err := NewPointerFromString("/items/name=z?:before")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected not to find any modifiers with optional matching index token"))
+
+		_, err = NewPointerFromString("/items/name~=^z:optional:next")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected not to find any modifiers with optional matching index token"))
+	})
+
+	It("returns error if string has optional modifier in non-matching token", func() {
+		_, err := NewPointerFromString("/key:optional")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find 'optional' modifier only with matching index token"))
+	})
+
+	It("returns error if regexp or glob condition value ends with '?'", func() {
+		_, err := NewPointerFromString("/items/name~=^ab?")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal(
+			"Expected regexp or glob condition value '^ab?' to not end with '?' (use '~2' for literal '?' or 'optional' modifier)"))
+
+		_, err = NewPointerFromString("/items/name=a,name*=a-*?:all")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal(
+			"Expected regexp or glob condition value 'a-*?' to not end with '?' (use '~2' for literal '?' or 'optional' modifier)"))
+	})
+
+	It("returns error if string includes invalid regular expression", func() {
+		_, err := NewPointerFromString("/name~=a(")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal(
+			"Expected to find valid regular expression for key 'name': error parsing regexp: missing closing ): `a(`"))
+	})
+
 	It("returns error if string has modifiers in key-token", func() {
 		_, err := NewPointerFromString("/key:prev")
 		Expect(err).To(HaveOccurred())
@@ -165,6 +270,13 @@ var _ = Describe("Pointer.Tokens", func() {
 			KeyToken{Key: "key"},
 			KeyToken{Key: "key2", Optional: true},
 		}},
+		// Commas only separate conditions when both sides include '='
+		{"/name=a,b", []Token{RootToken{}, MatchingIndexToken{Key: "name", Value: "a,b"}}},
+		{"/a,b=c", []Token{RootToken{}, MatchingIndexToken{Key: "a,b", Value: "c"}}},
+		{"/name=a,b,c=d", []Token{
+			RootToken{},
+			MatchingIndexToken{Key: "name", Value: "a,b", And: []MatchingCondition{{Key: "c", Value: "d"}}},
+		}},
 	}
 
 	parsingTestCases = append(parsingTestCases, testCases...)
@@ -177,6 +289,54 @@ var _ = Describe("Pointer.Tokens", func() {
 	}
 })
 
+var _ = Describe("Pointer.Expand", func() {
+	doc := map[interface{}]interface{}{
+		"igs": []interface{}{
+			map[interface{}]interface{}{"name": "a", "jobs": []interface{}{"j1", "j2"}},
+			map[interface{}]interface{}{"name": "b", "jobs": []interface{}{}},
+			map[interface{}]interface{}{"name": "a", "jobs": []interface{}{"j3"}},
+		},
+	}
+
+	It("returns pointers to every location referred to by wildcards and all matching items", func() {
+		ptrs, err := MustNewPointerFromString("/igs/name=a:all/jobs/*").Expand(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		var strs []string
+		for _, ptr := range ptrs {
+			strs = append(strs, ptr.String())
+		}
+		Expect(strs).To(Equal([]string{"/igs/0/jobs/0", "/igs/0/jobs/1", "/igs/2/jobs/0"}))
+	})
+
+	It("returns locations that wildcard operations are applied to", func() {
+		path := MustNewPointerFromString("/igs/*/owner?")
+
+		ptrs, err := path.Expand(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		res, err := ReplaceOp{Path: path, Value: "new"}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		for _, ptr := range ptrs {
+			Expect(FindOp{Path: ptr}.Apply(res)).To(Equal("new"))
+		}
+		Expect(ptrs).To(HaveLen(3))
+	})
+
+	It("returns pointer itself if it does not refer to multiple locations", func() {
+		ptr := MustNewPointerFromString("/igs/name=b/jobs")
+
+		Expect(ptr.Expand(doc)).To(Equal([]Pointer{ptr}))
+	})
+
+	It("returns an error if location cannot be found", func() {
+		_, err := MustNewPointerFromString("/other/*").Expand(doc)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find a map key 'other' for path '/other' (found map keys: 'igs')"))
+	})
+})
+
 var _ = Describe("Pointer.IsSet", func() {
 	It("returns true if there is at least one token", func() {
 		Expect(MustNewPointerFromString("").IsSet()).To(BeTrue())
@@ -204,3 +364,54 @@ var _ = Describe("Pointer.UnmarshalFlag", func() {
 		Expect(err.Error()).To(Equal("Expected to start with '/'"))
 	})
 })
+
+var _ = Describe("Pointer.Resolve", func() {
+	doc := map[interface{}]interface{}{
+		"igs": []interface{}{
+			map[interface{}]interface{}{"name": "a", "jobs": []interface{}{"j1", "j2", "j3"}},
+			map[interface{}]interface{}{"name": "b"},
+		},
+	}
+
+	It("returns pointer that only consists of map keys and array indices", func() {
+		cases := map[string]string{
+			"":                          "",
+			"/igs/name=a/jobs/-1":       "/igs/0/jobs/2",
+			"/igs/name=a/jobs/-1:prev":  "/igs/0/jobs/1",
+			"/igs/0:next/name":          "/igs/1/name",
+			"/igs?/name=b?/name?":       "/igs/1/name",
+			"/igs/name~=^a/jobs/0:next": "/igs/0/jobs/1",
+		}
+
+		for ptr, expectedPtr := range cases {
+			resolvedPtr, err := MustNewPointerFromString(ptr).Resolve(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resolvedPtr.String()).To(Equal(expectedPtr))
+		}
+
+		resolvedPtr, err := MustNewPointerFromString("/igs/name=b").Resolve(doc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resolvedPtr.Tokens()).To(Equal([]Token{RootToken{}, KeyToken{Key: "igs"}, IndexToken{Index: 1}}))
+	})
+
+	It("returns an error referring to the first missing part of the path even if it's optional", func() {
+		cases := map[string]string{
+			"/igs/name=a/other?/key": "Expected to find a map key 'other' for path '/igs/name=a/other' (found map keys: 'jobs', 'name')",
+			"/igs/name=c?/jobs":      "Expected to find exactly one matching array item for path '/igs/name=c' but found 0",
+			"/igs/2":                 "Expected to find array index '2' but found array of length '2' for path '/igs/2'",
+			"/igs/-":                 "Expected to not find token 'patch.AfterLastIndexToken' at path '/igs/-'",
+		}
+
+		for ptr, errMsg := range cases {
+			_, err := MustNewPointerFromString(ptr).Resolve(doc)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(errMsg))
+		}
+	})
+
+	It("returns an error if pointer refers to multiple locations", func() {
+		_, err := MustNewPointerFromString("/igs/*/name").Resolve(doc)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected path '/igs/*/name' to refer to a single location (expand wildcards first)"))
+	})
+})
@@ -0,0 +1,632 @@
+package patch
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// QueryResult is a value selected by Query along with a concrete pointer to it
+type QueryResult struct {
+	Path  Pointer
+	Value interface{}
+}
+
+// Query returns values (not copies) selected by a JSONPath-like expression
+// (e.g. '$.instance_groups[?(@.instances > 2)].name') in document order.
+// Returned pointers only consist of map key and array index tokens,
+// hence they could be used to construct operations for exactly those locations.
+// Missing keys and out of range indices select nothing.
+func Query(doc interface{}, expr string) ([]QueryResult, error) {
+	selectors, err := (&queryParser{expr: expr}).Parse()
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := querySelect([]queryNode{{tokens: []Token{RootToken{}}, value: doc}}, selectors)
+
+	results := []QueryResult{}
+
+	for _, node := range nodes {
+		results = append(results, QueryResult{Path: NewPointer(node.tokens), Value: node.value})
+	}
+
+	return results, nil
+}
+
+type queryNode struct {
+	tokens []Token
+	value  interface{}
+}
+
+func (n queryNode) child(token Token, value interface{}) queryNode {
+	tokens := append(append([]Token{}, n.tokens...), token)
+	return queryNode{tokens: tokens, value: value}
+}
+
+// children returns array items or map values (in key order)
+func (n queryNode) children() []queryNode {
+	var nodes []queryNode
+
+	switch typedVal := n.value.(type) {
+	case []interface{}:
+		for idx, item := range typedVal {
+			nodes = append(nodes, n.child(IndexToken{Index: idx}, item))
+		}
+
+	default:
+		typedMap, ok := newDocMap(n.value)
+		if ok {
+			for _, key := range typedMap.Keys() {
+				val, _ := typedMap.Get(key)
+				nodes = append(nodes, n.child(KeyToken{Key: key}, val))
+			}
+		}
+	}
+
+	return nodes
+}
+
+// descendants returns node itself followed by all nested nodes (depth first)
+func (n queryNode) descendants() []queryNode {
+	nodes := []queryNode{n}
+
+	for _, child := range n.children() {
+		nodes = append(nodes, child.descendants()...)
+	}
+
+	return nodes
+}
+
+func querySelect(nodes []queryNode, selectors []querySelector) []queryNode {
+	for _, selector := range selectors {
+		var selected []queryNode
+
+		for _, node := range nodes {
+			selected = append(selected, selector.Select(node)...)
+		}
+
+		nodes = selected
+	}
+
+	return nodes
+}
+
+type querySelector interface {
+	Select(queryNode) []queryNode
+}
+
+// queryNameSelector selects map value by key (e.g. '.name' or "['name']")
+type queryNameSelector struct {
+	Name string
+}
+
+func (s queryNameSelector) Select(node queryNode) []queryNode {
+	typedMap, ok := newDocMap(node.value)
+	if !ok {
+		return nil
+	}
+
+	val, found := typedMap.Get(s.Name)
+	if !found {
+		return nil
+	}
+
+	return []queryNode{node.child(KeyToken{Key: s.Name}, val)}
+}
+
+// queryIndexSelector selects array item (e.g. '[0]' or '[-1]' for the last item)
+type queryIndexSelector struct {
+	Index int
+}
+
+func (s queryIndexSelector) Select(node queryNode) []queryNode {
+	typedAry, ok := node.value.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	idx := s.Index
+	if idx < 0 {
+		idx += len(typedAry)
+	}
+
+	if idx < 0 || idx >= len(typedAry) {
+		return nil
+	}
+
+	return []queryNode{node.child(IndexToken{Index: idx}, typedAry[idx])}
+}
+
+// queryWildcardSelector selects every array item or map value (e.g. '.*' or '[*]')
+type queryWildcardSelector struct{}
+
+func (s queryWildcardSelector) Select(node queryNode) []queryNode {
+	return node.children()
+}
+
+// queryFilterSelector selects array items or map values satisfying expression (e.g. '[?(@.a == 1)]')
+type queryFilterSelector struct {
+	Expr queryExpr
+}
+
+func (s queryFilterSelector) Select(node queryNode) []queryNode {
+	var nodes []queryNode
+
+	for _, child := range node.children() {
+		if s.Expr.Eval(child.value) {
+			nodes = append(nodes, child)
+		}
+	}
+
+	return nodes
+}
+
+// queryDescendantSelector applies selector to node and all nested nodes (e.g. '..name')
+type queryDescendantSelector struct {
+	Selector querySelector
+}
+
+func (s queryDescendantSelector) Select(node queryNode) []queryNode {
+	var nodes []queryNode
+
+	for _, descendant := range node.descendants() {
+		nodes = append(nodes, s.Selector.Select(descendant)...)
+	}
+
+	return nodes
+}
+
+type queryExpr interface {
+	Eval(current interface{}) bool
+}
+
+type queryOrExpr struct {
+	Left, Right queryExpr
+}
+
+func (e queryOrExpr) Eval(current interface{}) bool {
+	return e.Left.Eval(current) || e.Right.Eval(current)
+}
+
+type queryAndExpr struct {
+	Left, Right queryExpr
+}
+
+func (e queryAndExpr) Eval(current interface{}) bool {
+	return e.Left.Eval(current) && e.Right.Eval(current)
+}
+
+type queryNotExpr struct {
+	Expr queryExpr
+}
+
+func (e queryNotExpr) Eval(current interface{}) bool {
+	return !e.Expr.Eval(current)
+}
+
+// queryExistsExpr checks that relative path refers to a value (e.g. '[?(@.name)]')
+type queryExistsExpr struct {
+	Operand queryOperand
+}
+
+func (e queryExistsExpr) Eval(current interface{}) bool {
+	_, found := e.Operand.Value(current)
+	return found
+}
+
+// queryCompareExpr compares two operands; missing values are only equal to each other,
+// numbers are compared by value and ordering is only defined for numbers and strings
+type queryCompareExpr struct {
+	Op          string
+	Left, Right queryOperand
+}
+
+func (e queryCompareExpr) Eval(current interface{}) bool {
+	left, leftFound := e.Left.Value(current)
+	right, rightFound := e.Right.Value(current)
+
+	bothFound := leftFound && rightFound
+
+	equal := leftFound == rightFound
+	if bothFound {
+		equal = reflect.DeepEqual(jsonPatchNormalize(left), jsonPatchNormalize(right))
+	}
+
+	switch e.Op {
+	case "==":
+		return equal
+	case "!=":
+		return !equal
+	case "<":
+		return bothFound && queryLess(left, right)
+	case "<=":
+		return equal || (bothFound && queryLess(left, right))
+	case ">":
+		return bothFound && queryLess(right, left)
+	case ">=":
+		return equal || (bothFound && queryLess(right, left))
+	default:
+		panic(fmt.Sprintf("Unknown comparison operator '%s'", e.Op))
+	}
+}
+
+func queryLess(left, right interface{}) bool {
+	switch typedLeft := jsonPatchNormalize(left).(type) {
+	case float64:
+		typedRight, ok := jsonPatchNormalize(right).(float64)
+		return ok && typedLeft < typedRight
+	case string:
+		typedRight, ok := right.(string)
+		return ok && typedLeft < typedRight
+	default:
+		return false
+	}
+}
+
+type queryOperand interface {
+	Value(current interface{}) (interface{}, bool)
+}
+
+type queryLiteralOperand struct {
+	Literal interface{}
+}
+
+func (o queryLiteralOperand) Value(_ interface{}) (interface{}, bool) { return o.Literal, true }
+
+// queryPathOperand refers to a value relative to the current item (e.g. '@.properties.port')
+type queryPathOperand struct {
+	Selectors []querySelector
+}
+
+func (o queryPathOperand) Value(current interface{}) (interface{}, bool) {
+	nodes := querySelect([]queryNode{{value: current}}, o.Selectors)
+	if len(nodes) != 1 {
+		return nil, false
+	}
+	return nodes[0].value, true
+}
+
+type queryParser struct {
+	expr string
+	pos  int
+}
+
+// Parse parses '$' followed by '.name', "['name']", '[0]', '.*', '[*]', '[?(expr)]'
+// or any of them prefixed with '..' to search all nested values
+func (p *queryParser) Parse() ([]querySelector, error) {
+	if !p.consume("$") {
+		return nil, p.expectedErr("'$'")
+	}
+
+	selectors, err := p.parseSegments(false)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos < len(p.expr) {
+		return nil, p.expectedErr("'.' or '['")
+	}
+
+	return selectors, nil
+}
+
+// parseSegments parses segments until it encounters unexpected character.
+// Relative paths (within filter expressions) are required to refer to a single value.
+func (p *queryParser) parseSegments(relative bool) ([]querySelector, error) {
+	var selectors []querySelector
+
+	for {
+		var (
+			selector querySelector
+			err      error
+		)
+
+		switch {
+		case p.peek(".."):
+			if relative {
+				return nil, p.expectedErr("only names and indices in relative path")
+			}
+
+			p.consume("..")
+
+			if p.peek("[") {
+				selector, err = p.parseBracket(relative)
+			} else {
+				selector, err = p.parseDot(relative)
+			}
+
+			selector = queryDescendantSelector{selector}
+
+		case p.consume("."):
+			selector, err = p.parseDot(relative)
+
+		case p.peek("["):
+			selector, err = p.parseBracket(relative)
+
+		default:
+			return selectors, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		selectors = append(selectors, selector)
+	}
+}
+
+func (p *queryParser) parseDot(relative bool) (querySelector, error) {
+	if p.consume("*") {
+		if relative {
+			return nil, p.expectedErr("only names and indices in relative path")
+		}
+		return queryWildcardSelector{}, nil
+	}
+
+	start := p.pos
+
+	for p.pos < len(p.expr) && !strings.ContainsRune(".[]()!=<>&| ", rune(p.expr[p.pos])) {
+		p.pos++
+	}
+
+	if p.pos == start {
+		return nil, p.expectedErr("name")
+	}
+
+	return queryNameSelector{p.expr[start:p.pos]}, nil
+}
+
+func (p *queryParser) parseBracket(relative bool) (querySelector, error) {
+	var (
+		selector querySelector
+		err      error
+	)
+
+	p.consume("[")
+	p.skipSpaces()
+
+	switch {
+	case p.peek("*"), p.peek("?"):
+		if relative {
+			return nil, p.expectedErr("only names and indices in relative path")
+		}
+
+		if p.consume("*") {
+			selector = queryWildcardSelector{}
+		} else {
+			p.consume("?")
+
+			var expr queryExpr
+
+			expr, err = p.parseOr()
+			selector = queryFilterSelector{expr}
+		}
+
+	case p.peek("'"), p.peek(`"`):
+		var name string
+
+		name, err = p.parseString()
+		selector = queryNameSelector{name}
+
+	default:
+		start := p.pos
+
+		for p.pos < len(p.expr) && strings.ContainsRune("-0123456789", rune(p.expr[p.pos])) {
+			p.pos++
+		}
+
+		idx, convErr := strconv.Atoi(p.expr[start:p.pos])
+		if convErr != nil {
+			p.pos = start
+			return nil, p.expectedErr("array index, quoted name, '*' or '?'")
+		}
+
+		selector = queryIndexSelector{idx}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpaces()
+
+	if !p.consume("]") {
+		return nil, p.expectedErr("']'")
+	}
+
+	return selector, nil
+}
+
+func (p *queryParser) parseOr() (queryExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.skipSpaces(); p.consume("||"); p.skipSpaces() {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		left = queryOrExpr{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *queryParser) parseAnd() (queryExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.skipSpaces(); p.consume("&&"); p.skipSpaces() {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		left = queryAndExpr{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *queryParser) parseUnary() (queryExpr, error) {
+	p.skipSpaces()
+
+	switch {
+	case p.consume("!"):
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return queryNotExpr{expr}, nil
+
+	case p.consume("("):
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		p.skipSpaces()
+
+		if !p.consume(")") {
+			return nil, p.expectedErr("')'")
+		}
+
+		return expr, nil
+
+	default:
+		return p.parseComparison()
+	}
+}
+
+func (p *queryParser) parseComparison() (queryExpr, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpaces()
+
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.consume(op) {
+			right, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+
+			return queryCompareExpr{Op: op, Left: left, Right: right}, nil
+		}
+	}
+
+	if _, ok := left.(queryPathOperand); !ok {
+		return nil, p.expectedErr("comparison operator")
+	}
+
+	return queryExistsExpr{left}, nil
+}
+
+func (p *queryParser) parseOperand() (queryOperand, error) {
+	p.skipSpaces()
+
+	switch {
+	case p.consume("@"):
+		selectors, err := p.parseSegments(true)
+		if err != nil {
+			return nil, err
+		}
+
+		return queryPathOperand{selectors}, nil
+
+	case p.peek("'"), p.peek(`"`):
+		str, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+
+		return queryLiteralOperand{str}, nil
+
+	case p.consume("true"):
+		return queryLiteralOperand{true}, nil
+
+	case p.consume("false"):
+		return queryLiteralOperand{false}, nil
+
+	case p.consume("null"):
+		return queryLiteralOperand{nil}, nil
+
+	default:
+		start := p.pos
+
+		for p.pos < len(p.expr) && strings.ContainsRune("+-.0123456789eE", rune(p.expr[p.pos])) {
+			p.pos++
+		}
+
+		str := p.expr[start:p.pos]
+
+		if idx, err := strconv.Atoi(str); err == nil {
+			return queryLiteralOperand{idx}, nil
+		}
+
+		if num, err := strconv.ParseFloat(str, 64); err == nil {
+			return queryLiteralOperand{num}, nil
+		}
+
+		p.pos = start
+
+		return nil, p.expectedErr("'@', string, number, 'true', 'false' or 'null'")
+	}
+}
+
+// parseString parses single or double quoted string; backslash escapes following character
+func (p *queryParser) parseString() (string, error) {
+	quote := p.expr[p.pos]
+	p.pos++
+
+	var str []byte
+
+	for p.pos < len(p.expr) {
+		char := p.expr[p.pos]
+		p.pos++
+
+		switch {
+		case char == quote:
+			return string(str), nil
+		case char == '\\' && p.pos < len(p.expr):
+			str = append(str, p.expr[p.pos])
+			p.pos++
+		default:
+			str = append(str, char)
+		}
+	}
+
+	return "", p.expectedErr(fmt.Sprintf("closing %c", quote))
+}
+
+func (p *queryParser) peek(str string) bool {
+	return strings.HasPrefix(p.expr[p.pos:], str)
+}
+
+func (p *queryParser) consume(str string) bool {
+	if p.peek(str) {
+		p.pos += len(str)
+		return true
+	}
+	return false
+}
+
+func (p *queryParser) skipSpaces() {
+	for p.pos < len(p.expr) && p.expr[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *queryParser) expectedErr(expected string) error {
+	return fmt.Errorf("Expected to find %s at position %d in query '%s'", expected, p.pos, p.expr)
+}
@@ -0,0 +1,173 @@
+package patch_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/SUSE/go-patch/patch"
+)
+
+var _ = Describe("Query", func() {
+	var doc interface{}
+
+	BeforeEach(func() {
+		doc = map[interface{}]interface{}{
+			"name": "dep",
+			"instance_groups": []interface{}{
+				map[interface{}]interface{}{
+					"name":      "worker-az1",
+					"instances": 3,
+					"jobs":      []interface{}{map[interface{}]interface{}{"name": "j1", "properties": map[interface{}]interface{}{"port": 80}}},
+				},
+				map[interface{}]interface{}{
+					"name":      "api",
+					"instances": 1,
+					"jobs":      []interface{}{map[interface{}]interface{}{"name": "j2"}},
+				},
+				map[interface{}]interface{}{
+					"name":      "worker-az2",
+					"instances": 5.0,
+					"lifecycle": "errand",
+				},
+			},
+		}
+	})
+
+	query := func(expr string) ([]string, []interface{}) {
+		results, err := Query(doc, expr)
+		Expect(err).ToNot(HaveOccurred())
+
+		paths := []string{}
+		values := []interface{}{}
+
+		for _, result := range results {
+			paths = append(paths, result.Path.String())
+			values = append(values, result.Value)
+		}
+
+		return paths, values
+	}
+
+	It("returns values along with concrete pointers", func() {
+		paths, values := query("$.instance_groups[?(@.instances > 2)].name")
+		Expect(paths).To(Equal([]string{"/instance_groups/0/name", "/instance_groups/2/name"}))
+		Expect(values).To(Equal([]interface{}{"worker-az1", "worker-az2"}))
+
+		results, err := Query(doc, "$.instance_groups[1]")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results[0].Path.Tokens()).To(Equal([]Token{RootToken{}, KeyToken{Key: "instance_groups"}, IndexToken{Index: 1}}))
+	})
+
+	It("returns pointers that could be used for operations", func() {
+		results, err := Query(doc, "$.instance_groups[?(@.name == 'api')].instances")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results).To(HaveLen(1))
+
+		res, err := ReplaceOp{Path: results[0].Path, Value: 2}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(FindOp{Path: MustNewPointerFromString("/instance_groups/name=api/instances")}.Apply(res)).To(Equal(2))
+	})
+
+	It("returns document itself for root", func() {
+		paths, values := query("$")
+		Expect(paths).To(Equal([]string{""}))
+		Expect(values).To(Equal([]interface{}{doc}))
+	})
+
+	It("selects map keys and array indices", func() {
+		paths, _ := query("$['instance_groups'][0][\"jobs\"][-1].properties.port")
+		Expect(paths).To(Equal([]string{"/instance_groups/0/jobs/0/properties/port"}))
+
+		paths, _ = query("$.instance_groups[-3].name")
+		Expect(paths).To(Equal([]string{"/instance_groups/0/name"}))
+	})
+
+	It("selects nothing for missing keys, out of range indices and mismatched types", func() {
+		for _, expr := range []string{"$.missing", "$.instance_groups[3]", "$.instance_groups[-4]", "$.name[0]", "$.name.key", "$.name.*"} {
+			paths, _ := query(expr)
+			Expect(paths).To(BeEmpty(), expr)
+		}
+	})
+
+	It("selects every array item or map value in key order with wildcards", func() {
+		paths, _ := query("$.instance_groups[*].instances")
+		Expect(paths).To(Equal([]string{
+			"/instance_groups/0/instances", "/instance_groups/1/instances", "/instance_groups/2/instances"}))
+
+		paths, _ = query("$.instance_groups[2].*")
+		Expect(paths).To(Equal([]string{
+			"/instance_groups/2/instances", "/instance_groups/2/lifecycle", "/instance_groups/2/name"}))
+	})
+
+	It("selects nested values with recursive descent", func() {
+		paths, values := query("$..jobs[*].name")
+		Expect(paths).To(Equal([]string{"/instance_groups/0/jobs/0/name", "/instance_groups/1/jobs/0/name"}))
+		Expect(values).To(Equal([]interface{}{"j1", "j2"}))
+
+		paths, _ = query("$..port")
+		Expect(paths).To(Equal([]string{"/instance_groups/0/jobs/0/properties/port"}))
+
+		paths, _ = query("$..[?(@.lifecycle)]")
+		Expect(paths).To(Equal([]string{"/instance_groups/2"}))
+	})
+
+	It("filters with comparisons, logical operators and existence checks", func() {
+		cases := map[string][]string{
+			"$.instance_groups[?(@.instances == 5)].name":                           {"/instance_groups/2/name"},
+			"$.instance_groups[?(@.instances != 3)].name":                           {"/instance_groups/1/name", "/instance_groups/2/name"},
+			"$.instance_groups[?(@.instances <= 3)].name":                           {"/instance_groups/0/name", "/instance_groups/1/name"},
+			"$.instance_groups[?(@.instances >= 3 && @.name != 'worker-az1')].name": {"/instance_groups/2/name"},
+			"$.instance_groups[?(@.name == \"api\" || @.lifecycle)].name":           {"/instance_groups/1/name", "/instance_groups/2/name"},
+			"$.instance_groups[?(!@.jobs)].name":                                    {"/instance_groups/2/name"},
+			"$.instance_groups[?(!(@.instances < 2 || @.instances > 4))].name":      {"/instance_groups/0/name"},
+			"$.instance_groups[?(@.name > 'b')].name":                               {"/instance_groups/0/name", "/instance_groups/2/name"},
+			"$.instance_groups[?(@.jobs[0].properties.port == 80)].name":            {"/instance_groups/0/name"},
+			"$.instance_groups[?(@.lifecycle == null)].name":                        {},
+			"$.instance_groups[?(@.instances > '2')].name":                          {},
+			"$.instance_groups[?@.lifecycle].name":                                  {"/instance_groups/2/name"},
+		}
+
+		for expr, expectedPaths := range cases {
+			paths, _ := query(expr)
+			Expect(paths).To(Equal(expectedPaths), expr)
+		}
+	})
+
+	It("works with documents decoded by encoding/json", func() {
+		var jsonDoc interface{}
+
+		err := json.Unmarshal([]byte(`{"a":[{"n":1},{"n":2.5}]}`), &jsonDoc)
+		Expect(err).ToNot(HaveOccurred())
+
+		results, err := Query(jsonDoc, "$.a[?(@.n == 1)]")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results).To(Equal([]QueryResult{
+			{Path: MustNewPointerFromString("/a/0"), Value: map[string]interface{}{"n": float64(1)}},
+		}))
+	})
+
+	It("returns an error if expression cannot be parsed", func() {
+		errs := map[string]string{
+			"instance_groups":   "Expected to find '$' at position 0 in query 'instance_groups'",
+			"$instance_groups":  "Expected to find '.' or '[' at position 1 in query '$instance_groups'",
+			"$.":                "Expected to find name at position 2 in query '$.'",
+			"$[a]":              "Expected to find array index, quoted name, '*' or '?' at position 2 in query '$[a]'",
+			"$[0":               "Expected to find ']' at position 3 in query '$[0'",
+			"$['a]":             "Expected to find closing ' at position 5 in query '$['a]'",
+			"$[?(@.a == 1]":     "Expected to find ')' at position 12 in query '$[?(@.a == 1]'",
+			"$[?(@.a == b)]":    "Expected to find '@', string, number, 'true', 'false' or 'null' at position 11 in query '$[?(@.a == b)]'",
+			"$[?(1)]":           "Expected to find comparison operator at position 5 in query '$[?(1)]'",
+			"$[?(@.a[*] == 1)]": "Expected to find only names and indices in relative path at position 8 in query '$[?(@.a[*] == 1)]'",
+			"$[?(@..a == 1)]":   "Expected to find only names and indices in relative path at position 5 in query '$[?(@..a == 1)]'",
+		}
+
+		for expr, errMsg := range errs {
+			_, err := Query(doc, expr)
+			Expect(err).To(HaveOccurred(), expr)
+			Expect(err.Error()).To(Equal(errMsg))
+		}
+	})
+})
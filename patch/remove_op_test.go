@@ -357,4 +357,139 @@ var _ = Describe("RemoveOp.Apply", func() {
 				"Expected to find a map at path '/abc' but found '[]interface {}'"))
 		})
 	})
+
+	Describe("wildcard", func() {
+		It("removes value within every array item", func() {
+			doc := []interface{}{
+				map[interface{}]interface{}{"name": "a", "disk": 1},
+				map[interface{}]interface{}{"name": "b", "disk": 2},
+			}
+
+			res, err := RemoveOp{Path: MustNewPointerFromString("/*/disk")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{
+				map[interface{}]interface{}{"name": "a"},
+				map[interface{}]interface{}{"name": "b"},
+			}))
+		})
+
+		It("removes every array item and map value", func() {
+			res, err := RemoveOp{Path: MustNewPointerFromString("/abc/*")}.Apply(
+				map[interface{}]interface{}{"abc": []interface{}{1, 2, 3}})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{"abc": []interface{}{}}))
+
+			res, err = RemoveOp{Path: MustNewPointerFromString("/*")}.Apply(
+				map[interface{}]interface{}{"a": 1, "b": 2})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{}))
+		})
+
+		It("removes values only from items that have them if path is optional", func() {
+			doc := []interface{}{
+				map[interface{}]interface{}{"name": "a", "disk": 1},
+				map[interface{}]interface{}{"name": "b"},
+			}
+
+			_, err := RemoveOp{Path: MustNewPointerFromString("/*/disk")}.Apply(doc)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected to find a map key 'disk' for path '/1/disk' (found map keys: 'name')"))
+
+			res, err := RemoveOp{Path: MustNewPointerFromString("/*?/disk")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{
+				map[interface{}]interface{}{"name": "a"},
+				map[interface{}]interface{}{"name": "b"},
+			}))
+		})
+
+		It("does nothing for empty or optional missing collections", func() {
+			doc := map[interface{}]interface{}{"abc": map[interface{}]interface{}{}}
+
+			res, err := RemoveOp{Path: MustNewPointerFromString("/abc/*/disk")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(doc))
+
+			res, err = RemoveOp{Path: MustNewPointerFromString("/xyz/*?/disk")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(doc))
+		})
+	})
+
+	Describe("all matching array items", func() {
+		It("removes every matching item", func() {
+			doc := []interface{}{
+				map[interface{}]interface{}{"name": "a"},
+				map[interface{}]interface{}{"name": "b"},
+				map[interface{}]interface{}{"name": "a"},
+			}
+
+			res, err := RemoveOp{Path: MustNewPointerFromString("/name=a:all")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{map[interface{}]interface{}{"name": "b"}}))
+		})
+
+		It("does nothing if no items match and matching is optional", func() {
+			doc := []interface{}{map[interface{}]interface{}{"name": "b"}}
+
+			res, err := RemoveOp{Path: MustNewPointerFromString("/name=a?:all")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(doc))
+		})
+	})
+
+	Describe("array item with multiple conditions or nested keys", func() {
+		It("removes array item that satisfies all conditions", func() {
+			doc := []interface{}{
+				map[interface{}]interface{}{"name": "a", "properties": map[interface{}]interface{}{"port": "80"}},
+				map[interface{}]interface{}{"name": "a", "properties": map[interface{}]interface{}{"port": "81"}},
+			}
+
+			res, err := RemoveOp{Path: MustNewPointerFromString("/name=a,properties.port=81")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{
+				map[interface{}]interface{}{"name": "a", "properties": map[interface{}]interface{}{"port": "80"}},
+			}))
+		})
+	})
+
+	Describe("array item with typed values", func() {
+		It("removes array item with matching number value", func() {
+			doc := []interface{}{
+				map[interface{}]interface{}{"port": 80},
+				map[interface{}]interface{}{"port": 8080},
+			}
+
+			res, err := RemoveOp{Path: MustNewPointerFromString("/port=8080")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{map[interface{}]interface{}{"port": 80}}))
+		})
+	})
+
+	Describe("array item with regexp or glob conditions", func() {
+		doc := []interface{}{
+			map[interface{}]interface{}{"name": "worker-az1"},
+			map[interface{}]interface{}{"name": "api-az1"},
+			map[interface{}]interface{}{"name": "worker-az2"},
+		}
+
+		It("removes array item matching regexp or glob", func() {
+			res, err := RemoveOp{Path: MustNewPointerFromString("/name~=^api-")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{
+				map[interface{}]interface{}{"name": "worker-az1"},
+				map[interface{}]interface{}{"name": "worker-az2"},
+			}))
+
+			res, err = RemoveOp{Path: MustNewPointerFromString("/name*=worker-*:all")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{map[interface{}]interface{}{"name": "api-az1"}}))
+		})
+
+		It("does nothing if no items match and matching is optional", func() {
+			res, err := RemoveOp{Path: MustNewPointerFromString("/name~=^db-:optional")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(doc))
+		})
+	})
 })
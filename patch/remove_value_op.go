@@ -0,0 +1,56 @@
+package patch
+
+import (
+	"reflect"
+)
+
+// RemoveValueOp removes all array items equal to value from an array at given path.
+// Nothing is removed if there are no such items or if array is optional and missing.
+type RemoveValueOp struct {
+	Path  Pointer
+	Value interface{}
+}
+
+func (op RemoveValueOp) path() Pointer           { return op.Path }
+func (op RemoveValueOp) withPath(ptr Pointer) Op { op.Path = ptr; return op }
+
+func (op RemoveValueOp) Apply(doc interface{}) (interface{}, error) {
+	return op.apply(doc, nil)
+}
+
+func (op RemoveValueOp) apply(doc interface{}, m matchers) (interface{}, error) {
+	if res, found, err := applyWildcard(op, doc, m); found {
+		return res, err
+	}
+
+	ops, err := op.removeOps(doc, m)
+	if err != nil {
+		return nil, err
+	}
+
+	return ops.apply(doc, m)
+}
+
+// removeOps returns operations that remove equal items from last to first
+func (op RemoveValueOp) removeOps(doc interface{}, m matchers) (Ops, error) {
+	loc, items, err := findArray(op.Path, doc, m)
+	if err != nil {
+		return nil, err
+	}
+
+	if loc.missing {
+		return Ops{}, nil
+	}
+
+	ops := Ops{}
+	value := jsonPatchNormalize(op.Value)
+
+	for i := len(items) - 1; i >= 0; i-- {
+		if reflect.DeepEqual(jsonPatchNormalize(items[i]), value) {
+			tokens := append(append([]Token{}, loc.tokens...), IndexToken{Index: i})
+			ops = append(ops, RemoveOp{Path: NewPointer(tokens)})
+		}
+	}
+
+	return ops, nil
+}
@@ -0,0 +1,56 @@
+package patch_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/SUSE/go-patch/patch"
+)
+
+var _ = Describe("RemoveValueOp.Apply", func() {
+	It("removes all equal items", func() {
+		op := RemoveValueOp{Path: MustNewPointerFromString("/tags"), Value: "a"}
+
+		res, err := op.Apply(parseYAML(`{"tags":["a","b","a",{"a":1},"c","a"]}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(parseYAML(`{"tags":["b",{"a":1},"c"]}`)))
+
+		res, err = op.Apply(res)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(parseYAML(`{"tags":["b",{"a":1},"c"]}`)))
+	})
+
+	It("compares complex values regardless of map flavor and number types", func() {
+		doc := map[string]interface{}{
+			"items": []interface{}{
+				map[string]interface{}{"size": float64(1)},
+				map[string]interface{}{"size": float64(2)},
+			},
+		}
+
+		res, err := RemoveValueOp{Path: MustNewPointerFromString("/items"), Value: parseYAML(`{"size":1}`)}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(map[string]interface{}{
+			"items": []interface{}{map[string]interface{}{"size": float64(2)}},
+		}))
+	})
+
+	It("removes nothing if optional array is missing", func() {
+		res, err := RemoveValueOp{Path: MustNewPointerFromString("/a?/tags"), Value: "a"}.Apply(parseYAML(`{}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(parseYAML(`{}`)))
+	})
+
+	It("removes items from every array matched by wildcard", func() {
+		res, err := RemoveValueOp{Path: MustNewPointerFromString("/*/tags"), Value: "a"}.Apply(
+			parseYAML(`[{"tags":["a","b"]},{"tags":["a"]}]`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(parseYAML(`[{"tags":["b"]},{"tags":[]}]`)))
+	})
+
+	It("returns an error if it's not an array", func() {
+		_, err := RemoveValueOp{Path: MustNewPointerFromString("/tags"), Value: "a"}.Apply(parseYAML(`{"tags":"a"}`))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find an array at path '/tags' but found 'string'"))
+	})
+})
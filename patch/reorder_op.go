@@ -0,0 +1,71 @@
+package patch
+
+import (
+	"fmt"
+)
+
+// ReorderOp moves array item referred to by given path (e.g. '/releases/name=foo')
+// within the same array so that it ends up at given index; negative index counts
+// from the end (e.g. -1 moves item to the end). Nothing is moved if item is optional and missing.
+type ReorderOp struct {
+	Path  Pointer
+	Index int
+}
+
+func (op ReorderOp) path() Pointer           { return op.Path }
+func (op ReorderOp) withPath(ptr Pointer) Op { op.Path = ptr; return op }
+
+func (op ReorderOp) Apply(doc interface{}) (interface{}, error) {
+	return op.apply(doc, nil)
+}
+
+func (op ReorderOp) apply(doc interface{}, m matchers) (interface{}, error) {
+	if res, found, err := applyWildcard(op, doc, m); found {
+		return res, err
+	}
+
+	arrayPath, from, found, err := op.locate(doc, m)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return doc, nil
+	}
+
+	_, items, err := findArray(arrayPath, doc, m)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := ArrayIndex{Index: op.Index, Array: items, Path: op.Path}.Concrete()
+	if err != nil {
+		return nil, err
+	}
+
+	item := items[from]
+
+	if from < to {
+		copy(items[from:to], items[from+1:to+1])
+	} else {
+		copy(items[to+1:from+1], items[to:from])
+	}
+
+	items[to] = item
+
+	return doc, nil
+}
+
+// locate returns concrete path of the array and index of the item within it
+func (op ReorderOp) locate(doc interface{}, m matchers) (Pointer, int, bool, error) {
+	loc, err := resolvePointer(op.Path, doc, false, m)
+	if err != nil || loc.missing {
+		return Pointer{}, 0, false, err
+	}
+
+	idxToken, ok := loc.tokens[len(loc.tokens)-1].(IndexToken)
+	if !ok || len(loc.tokens) == 1 {
+		return Pointer{}, 0, false, fmt.Errorf("Expected path '%s' to refer to an array item", op.Path)
+	}
+
+	return NewPointer(loc.tokens[:len(loc.tokens)-1]), idxToken.Index, true, nil
+}
@@ -0,0 +1,76 @@
+package patch_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/SUSE/go-patch/patch"
+)
+
+var _ = Describe("ReorderOp.Apply", func() {
+	reorder := func(path string, index int) interface{} {
+		doc := parseYAML(`{"releases":[{"name":"a"},{"name":"b"},{"name":"c"},{"name":"d"}]}`)
+
+		res, err := ReorderOp{Path: MustNewPointerFromString(path), Index: index}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		return res
+	}
+
+	It("moves matched item to given index", func() {
+		Expect(reorder("/releases/name=c", 0)).To(Equal(
+			parseYAML(`{"releases":[{"name":"c"},{"name":"a"},{"name":"b"},{"name":"d"}]}`)))
+
+		Expect(reorder("/releases/name=a", 2)).To(Equal(
+			parseYAML(`{"releases":[{"name":"b"},{"name":"c"},{"name":"a"},{"name":"d"}]}`)))
+
+		Expect(reorder("/releases/name=b", 1)).To(Equal(
+			parseYAML(`{"releases":[{"name":"a"},{"name":"b"},{"name":"c"},{"name":"d"}]}`)))
+	})
+
+	It("allows negative index counting from the end", func() {
+		Expect(reorder("/releases/name=a", -1)).To(Equal(
+			parseYAML(`{"releases":[{"name":"b"},{"name":"c"},{"name":"d"},{"name":"a"}]}`)))
+
+		Expect(reorder("/releases/name=d", -4)).To(Equal(
+			parseYAML(`{"releases":[{"name":"d"},{"name":"a"},{"name":"b"},{"name":"c"}]}`)))
+	})
+
+	It("resolves item path the same way as other operations", func() {
+		Expect(reorder("/releases/-1", 0)).To(Equal(
+			parseYAML(`{"releases":[{"name":"d"},{"name":"a"},{"name":"b"},{"name":"c"}]}`)))
+
+		Expect(reorder("/releases/name=b:next", 0)).To(Equal(
+			parseYAML(`{"releases":[{"name":"c"},{"name":"a"},{"name":"b"},{"name":"d"}]}`)))
+	})
+
+	It("does nothing if optional item is missing", func() {
+		Expect(reorder("/releases/name=x?", 0)).To(Equal(
+			parseYAML(`{"releases":[{"name":"a"},{"name":"b"},{"name":"c"},{"name":"d"}]}`)))
+	})
+
+	It("moves every item matched by wildcard", func() {
+		res, err := ReorderOp{Path: MustNewPointerFromString("/*/name=b"), Index: 0}.Apply(
+			parseYAML(`[[{"name":"a"},{"name":"b"}],[{"name":"b"}]]`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(parseYAML(`[[{"name":"b"},{"name":"a"}],[{"name":"b"}]]`)))
+	})
+
+	It("returns an error if index is out of bounds", func() {
+		_, err := ReorderOp{Path: MustNewPointerFromString("/releases/0"), Index: 1}.Apply(parseYAML(`{"releases":[1]}`))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find array index '1' but found array of length '1' for path '/releases/0'"))
+	})
+
+	It("returns an error if path does not refer to an array item", func() {
+		_, err := ReorderOp{Path: MustNewPointerFromString("/releases"), Index: 0}.Apply(parseYAML(`{"releases":[1]}`))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected path '/releases' to refer to an array item"))
+	})
+
+	It("returns an error if item is missing", func() {
+		_, err := ReorderOp{Path: MustNewPointerFromString("/releases/name=x"), Index: 0}.Apply(parseYAML(`{"releases":[]}`))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Expected to find exactly one matching array item for path '/releases/name=x'"))
+	})
+})
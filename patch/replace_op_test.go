@@ -321,6 +321,15 @@ var _ = Describe("ReplaceOp.Apply", func() {
 			}))
 		})
 
+		It("returns an error instead of ignoring modifiers if optional matching item does not exist", func() {
+			doc := map[interface{}]interface{}{"items": []interface{}{"a", "b"}}
+
+			_, err := ReplaceOp{Path: MustNewPointerFromString("/items?/name=z:before"), Value: "x"}.Apply(doc)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(
+				"Expected to find exactly one matching array item for path '/items?/name=z:before' but found 0"))
+		})
+
 		It("returns an error if it's not an array is being accessed", func() {
 			_, err := ReplaceOp{Path: MustNewPointerFromString("/key=val")}.Apply(map[interface{}]interface{}{})
 			Expect(err).To(HaveOccurred())
@@ -487,4 +496,334 @@ var _ = Describe("ReplaceOp.Apply", func() {
 				"Expected to find a map at path '/abc' but found '[]interface {}'"))
 		})
 	})
+
+	Describe("wildcard", func() {
+		It("replaces value within every array item", func() {
+			doc := map[interface{}]interface{}{
+				"igs": []interface{}{
+					map[interface{}]interface{}{"name": "a"},
+					map[interface{}]interface{}{"name": "b", "disk": 1},
+				},
+			}
+
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/igs/*/disk?"), Value: 10}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{
+				"igs": []interface{}{
+					map[interface{}]interface{}{"name": "a", "disk": 10},
+					map[interface{}]interface{}{"name": "b", "disk": 10},
+				},
+			}))
+		})
+
+		It("replaces every array item and map value with its own copy of value", func() {
+			value := map[interface{}]interface{}{"a": "b"}
+
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/*"), Value: value}.Apply([]interface{}{1, 2})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{value, value}))
+
+			res.([]interface{})[0].(map[interface{}]interface{})["a"] = "c"
+			Expect(res.([]interface{})[1]).To(Equal(value))
+
+			res, err = ReplaceOp{Path: MustNewPointerFromString("/*"), Value: 1}.Apply(
+				map[interface{}]interface{}{"a": "x", "b": "y"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{"a": 1, "b": 1}))
+		})
+
+		It("replaces values within nested wildcards", func() {
+			doc := []interface{}{
+				map[interface{}]interface{}{"jobs": []interface{}{
+					map[interface{}]interface{}{"name": "a"},
+				}},
+				map[interface{}]interface{}{"jobs": []interface{}{
+					map[interface{}]interface{}{"name": "b"},
+					map[interface{}]interface{}{"name": "c"},
+				}},
+			}
+
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/*/jobs/*/release?"), Value: "r"}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{
+				map[interface{}]interface{}{"jobs": []interface{}{
+					map[interface{}]interface{}{"name": "a", "release": "r"},
+				}},
+				map[interface{}]interface{}{"jobs": []interface{}{
+					map[interface{}]interface{}{"name": "b", "release": "r"},
+					map[interface{}]interface{}{"name": "c", "release": "r"},
+				}},
+			}))
+		})
+
+		It("does nothing for empty collections", func() {
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/abc/*/disk"), Value: 1}.Apply(
+				map[interface{}]interface{}{"abc": []interface{}{}})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{"abc": []interface{}{}}))
+		})
+
+		It("creates missing keys within items only if they are optional", func() {
+			doc := []interface{}{
+				map[interface{}]interface{}{"a": map[interface{}]interface{}{"b": 0}},
+				map[interface{}]interface{}{},
+			}
+
+			_, err := ReplaceOp{Path: MustNewPointerFromString("/*/a/b"), Value: 1}.Apply(doc)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected to find a map key 'a' for path '/1/a' (found no other map keys)"))
+
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/*?/a/b"), Value: 1}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{
+				map[interface{}]interface{}{"a": map[interface{}]interface{}{"b": 1}},
+				map[interface{}]interface{}{"a": map[interface{}]interface{}{"b": 1}},
+			}))
+		})
+
+		It("does nothing if collection is missing and wildcard is optional", func() {
+			doc := map[interface{}]interface{}{"abc": 1}
+
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/xyz/*?/disk"), Value: 1}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{"abc": 1}))
+
+			res, err = ReplaceOp{Path: MustNewPointerFromString("/xyz?/*/disk"), Value: 1}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{"abc": 1}))
+
+			_, err = ReplaceOp{Path: MustNewPointerFromString("/xyz/*/disk"), Value: 1}.Apply(doc)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected to find a map key 'xyz' for path '/xyz' (found map keys: 'abc')"))
+		})
+
+		It("returns an error if it's not a map or an array", func() {
+			_, err := ReplaceOp{Path: MustNewPointerFromString("/abc/*"), Value: 1}.Apply(
+				map[interface{}]interface{}{"abc": 1})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected to find a map or an array at path '/abc/*' but found 'int'"))
+		})
+	})
+
+	Describe("all matching array items", func() {
+		doc := func() interface{} {
+			return []interface{}{
+				map[interface{}]interface{}{"name": "a", "v": 1},
+				map[interface{}]interface{}{"name": "b"},
+				map[interface{}]interface{}{"name": "a"},
+			}
+		}
+
+		It("replaces value within every matching item", func() {
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/name=a:all/v?"), Value: 10}.Apply(doc())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{
+				map[interface{}]interface{}{"name": "a", "v": 10},
+				map[interface{}]interface{}{"name": "b"},
+				map[interface{}]interface{}{"name": "a", "v": 10},
+			}))
+		})
+
+		It("inserts relative to every matching item", func() {
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/name=a:all:after"), Value: "x"}.Apply(doc())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{
+				map[interface{}]interface{}{"name": "a", "v": 1},
+				"x",
+				map[interface{}]interface{}{"name": "b"},
+				map[interface{}]interface{}{"name": "a"},
+				"x",
+			}))
+		})
+
+		It("returns an error if no items match unless matching is optional", func() {
+			_, err := ReplaceOp{Path: MustNewPointerFromString("/name=c:all/v"), Value: 10}.Apply(doc())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected to find at least one matching array item for path '/name=c:all'"))
+
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/name=c?:all/v"), Value: 10}.Apply(doc())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(doc()))
+		})
+
+		It("returns an error if it's not an array", func() {
+			_, err := ReplaceOp{Path: MustNewPointerFromString("/name=a:all"), Value: 10}.Apply(
+				map[interface{}]interface{}{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected to find an array at path '/name=a:all' but found 'map[interface {}]interface {}'"))
+		})
+	})
+
+	Describe("array item with multiple conditions or nested keys", func() {
+		doc := func() interface{} {
+			return []interface{}{
+				map[interface{}]interface{}{"name": "a", "release": "r1", "properties": map[interface{}]interface{}{"port": "80"}},
+				map[interface{}]interface{}{"name": "a", "release": "r2", "properties": map[interface{}]interface{}{"port": "81"}},
+			}
+		}
+
+		It("replaces array item that satisfies all conditions", func() {
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/name=a,release=r2/v?"), Value: 1}.Apply(doc())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.([]interface{})[1]).To(Equal(map[interface{}]interface{}{
+				"name": "a", "release": "r2", "properties": map[interface{}]interface{}{"port": "81"}, "v": 1}))
+		})
+
+		It("replaces array item with matching nested key", func() {
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/properties.port=80/v?"), Value: 1}.Apply(doc())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.([]interface{})[0]).To(Equal(map[interface{}]interface{}{
+				"name": "a", "release": "r1", "properties": map[interface{}]interface{}{"port": "80"}, "v": 1}))
+		})
+
+		It("prefers keys that literally include dots", func() {
+			doc := []interface{}{
+				map[interface{}]interface{}{"a.b": "c"},
+				map[interface{}]interface{}{"a": map[interface{}]interface{}{"b": "d"}},
+			}
+
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/a.b=c/v?"), Value: 1}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.([]interface{})[0]).To(Equal(map[interface{}]interface{}{"a.b": "c", "v": 1}))
+		})
+
+		It("appends missing array item that satisfies all conditions", func() {
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/name=b,properties.port=82?/v"), Value: 1}.Apply(doc())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.([]interface{})[2]).To(Equal(map[interface{}]interface{}{
+				"name": "b", "properties": map[interface{}]interface{}{"port": 82}, "v": 1}))
+		})
+
+		It("returns an error if multiple items satisfy all conditions", func() {
+			_, err := ReplaceOp{Path: MustNewPointerFromString("/name=a,release=r1,name=a"), Value: 1}.Apply(
+				append(doc().([]interface{}), doc().([]interface{})...))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(
+				"Expected to find exactly one matching array item for path '/name=a,release=r1,name=a' but found 2"))
+		})
+	})
+
+	Describe("array item with typed values", func() {
+		It("replaces array item with matching number, boolean or null value", func() {
+			doc := []interface{}{
+				map[interface{}]interface{}{"port": 8080},
+				map[interface{}]interface{}{"port": 1.5, "enabled": true},
+				map[interface{}]interface{}{"port": nil},
+			}
+
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/port=8080/v?"), Value: 1}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+
+			res, err = ReplaceOp{Path: MustNewPointerFromString("/port=1.5,enabled=true/v?"), Value: 2}.Apply(res)
+			Expect(err).ToNot(HaveOccurred())
+
+			res, err = ReplaceOp{Path: MustNewPointerFromString("/port=null/v?"), Value: 3}.Apply(res)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(res).To(Equal([]interface{}{
+				map[interface{}]interface{}{"port": 8080, "v": 1},
+				map[interface{}]interface{}{"port": 1.5, "enabled": true, "v": 2},
+				map[interface{}]interface{}{"port": nil, "v": 3},
+			}))
+		})
+
+		It("prefers items with matching string values", func() {
+			doc := []interface{}{
+				map[interface{}]interface{}{"port": 8080},
+				map[interface{}]interface{}{"port": "8080"},
+			}
+
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/port=8080/v?"), Value: 1}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{
+				map[interface{}]interface{}{"port": 8080},
+				map[interface{}]interface{}{"port": "8080", "v": 1},
+			}))
+		})
+
+		It("creates missing array item with typed values that is matched when applied again", func() {
+			doc := []interface{}{map[interface{}]interface{}{"port": 80}}
+			op := ReplaceOp{Path: MustNewPointerFromString("/port=8080,enabled=true,name=a?/v"), Value: 1}
+
+			res, err := op.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+
+			res, err = op.Apply(res)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(res).To(Equal([]interface{}{
+				map[interface{}]interface{}{"port": 80},
+				map[interface{}]interface{}{"port": 8080, "enabled": true, "name": "a", "v": 1},
+			}))
+		})
+
+		It("does not match empty value to null", func() {
+			doc := []interface{}{map[interface{}]interface{}{"name": nil}}
+
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/name=?/v"), Value: 1}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{
+				map[interface{}]interface{}{"name": nil},
+				map[interface{}]interface{}{"name": "", "v": 1},
+			}))
+		})
+	})
+
+	Describe("array item with regexp or glob conditions", func() {
+		doc := func() interface{} {
+			return []interface{}{
+				map[interface{}]interface{}{"name": "worker-az1"},
+				map[interface{}]interface{}{"name": "api-az1"},
+				map[interface{}]interface{}{"name": "worker-az2"},
+				map[interface{}]interface{}{"name": 1},
+			}
+		}
+
+		It("replaces array item matching regexp", func() {
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/name~=^api-/v?"), Value: 1}.Apply(doc())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.([]interface{})[1]).To(Equal(map[interface{}]interface{}{"name": "api-az1", "v": 1}))
+		})
+
+		It("replaces array item matching glob", func() {
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/name*=worker-*2/v?"), Value: 1}.Apply(doc())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.([]interface{})[2]).To(Equal(map[interface{}]interface{}{"name": "worker-az2", "v": 1}))
+		})
+
+		It("replaces every matching array item", func() {
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/name*=worker-*:all/v?"), Value: 1}.Apply(doc())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{
+				map[interface{}]interface{}{"name": "worker-az1", "v": 1},
+				map[interface{}]interface{}{"name": "api-az1"},
+				map[interface{}]interface{}{"name": "worker-az2", "v": 1},
+				map[interface{}]interface{}{"name": 1},
+			}))
+		})
+
+		It("only matches whole values with glob", func() {
+			_, err := ReplaceOp{Path: MustNewPointerFromString("/name*=worker"), Value: 1}.Apply(doc())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected to find exactly one matching array item for path '/name*=worker' but found 0"))
+		})
+
+		It("returns an error if multiple items match", func() {
+			_, err := ReplaceOp{Path: MustNewPointerFromString("/name~=^worker-"), Value: 1}.Apply(doc())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected to find exactly one matching array item for path '/name~=^worker-' but found 2"))
+		})
+
+		It("returns an error instead of creating missing item", func() {
+			_, err := ReplaceOp{Path: MustNewPointerFromString("/name~=^db-:optional/v"), Value: 1}.Apply(doc())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(
+				"Expected to find matching array item for path '/name~=^db-:optional' (items cannot be created for regexp or glob conditions)"))
+
+			_, err = ReplaceOp{Path: MustNewPointerFromString("/name=db,name*=db*:optional"), Value: 1}.Apply(doc())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(
+				"Expected to find matching array item for path '/name=db,name*=db*:optional' (items cannot be created for regexp or glob conditions)"))
+		})
+	})
 })
@@ -0,0 +1,93 @@
+package patch
+
+import (
+	"fmt"
+)
+
+// Resolve returns pointer to the same location that only consists of map key and array index tokens
+// (e.g. '/name=a/-1:prev' might become '/2/3'), for example to record which array item was modified.
+// Location is expected to exist; otherwise an error refers to the first missing
+// part of the path even if it's optional.
+func (p Pointer) Resolve(doc interface{}) (Pointer, error) {
+	if _, found := wildcardIndex(p); found {
+		return Pointer{}, fmt.Errorf("Expected path '%s' to refer to a single location (expand wildcards first)", p)
+	}
+
+	loc, err := resolvePointer(p, doc, false, nil)
+	if err != nil {
+		return Pointer{}, err
+	}
+
+	if loc.missing {
+		// Required version of the path results in an error for missing location
+		_, err = resolvePointer(p.required(), doc, false, nil)
+		if err != nil {
+			return Pointer{}, err
+		}
+
+		return Pointer{}, fmt.Errorf("Expected to find '%s'", p)
+	}
+
+	return NewPointer(loc.tokens), nil
+}
+
+// required returns the same pointer without optional tokens
+func (p Pointer) required() Pointer {
+	var tokens []Token
+
+	for _, token := range p.tokens {
+		switch typedToken := token.(type) {
+		case KeyToken:
+			typedToken.Optional = false
+			token = typedToken
+		case MatchingIndexToken:
+			typedToken.Optional = false
+			token = typedToken
+		}
+
+		tokens = append(tokens, token)
+	}
+
+	return NewPointer(tokens)
+}
+
+// lastKeyOptional returns the same pointer with last map key token marked optional
+// (e.g. for operations that create last key without requiring '?' in the path)
+func (p Pointer) lastKeyOptional() Pointer {
+	tokens := append([]Token{}, p.tokens...)
+
+	if keyToken, ok := tokens[len(tokens)-1].(KeyToken); ok {
+		keyToken.Optional = true
+		tokens[len(tokens)-1] = keyToken
+	}
+
+	return NewPointer(tokens)
+}
+
+type pointerLocation struct {
+	tokens  []Token // only contains root, index and key tokens
+	missing bool    // true if location does not exist yet (e.g. optional key or array insertion)
+}
+
+// resolvePointer finds concrete location for given pointer, stopping at the first
+// location that does not exist (only possible when optional tokens or insertion are used)
+func resolvePointer(ptr Pointer, doc interface{}, insertion bool, m matchers) (pointerLocation, error) {
+	return resolveWalkDocPointer(ptr, doc, ifaceWalkDoc{matchers: m}, insertion)
+}
+
+// resolveWalkDocPointer is resolvePointer for any document representation (e.g. yaml.v3 nodes)
+func resolveWalkDocPointer(ptr Pointer, obj interface{}, doc walkDoc, insertion bool) (pointerLocation, error) {
+	loc := pointerLocation{tokens: []Token{RootToken{}}}
+
+	w := walker{Path: ptr, Doc: doc, Missing: walkMissingStop, Insertion: insertion}
+
+	err := w.Walk(obj, func(interface{}) {}, func(walkLoc walkLocation) error {
+		loc = pointerLocation{walkLoc.Tokens, !walkLoc.Found}
+		return nil
+	})
+	if err != nil {
+		return pointerLocation{}, err
+	}
+
+	return loc, nil
+}
@@ -0,0 +1,86 @@
+package patch
+
+import (
+	"sort"
+)
+
+// SortOp sorts array at given path in place by item values or, if Key is set,
+// by values of given key (e.g. 'name' or nested 'properties.id').
+// Nulls come first, followed by booleans, numbers and strings; items of other types
+// (and items without the key) are placed last. Sorting is stable.
+// Nothing is sorted if array is optional and missing.
+type SortOp struct {
+	Path Pointer
+	Key  string
+}
+
+func (op SortOp) path() Pointer           { return op.Path }
+func (op SortOp) withPath(ptr Pointer) Op { op.Path = ptr; return op }
+
+func (op SortOp) Apply(doc interface{}) (interface{}, error) {
+	return op.apply(doc, nil)
+}
+
+func (op SortOp) apply(doc interface{}, m matchers) (interface{}, error) {
+	if res, found, err := applyWildcard(op, doc, m); found {
+		return res, err
+	}
+
+	_, items, err := findArray(op.Path, doc, m)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return op.less(items[i], items[j])
+	})
+
+	return doc, nil
+}
+
+func (op SortOp) less(a, b interface{}) bool {
+	aVal, aRank := op.sortValue(a)
+	bVal, bRank := op.sortValue(b)
+
+	if aRank != bRank {
+		return aRank < bRank
+	}
+
+	switch typedA := aVal.(type) {
+	case bool:
+		return !typedA && bVal.(bool)
+	case float64:
+		return typedA < bVal.(float64)
+	case string:
+		return typedA < bVal.(string)
+	default:
+		return false
+	}
+}
+
+// sortValue returns comparable value and its rank among other types
+func (op SortOp) sortValue(item interface{}) (interface{}, int) {
+	val := item
+
+	if len(op.Key) > 0 {
+		var found bool
+
+		val, found = matchingValue(item, op.Key)
+		if !found {
+			return nil, 5
+		}
+	}
+
+	switch typedVal := jsonPatchNormalize(val).(type) {
+	case nil:
+		return nil, 0
+	case bool:
+		return typedVal, 1
+	case float64:
+		return typedVal, 2
+	case string:
+		return typedVal, 3
+	default:
+		return nil, 4
+	}
+}
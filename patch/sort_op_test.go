@@ -0,0 +1,56 @@
+package patch_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/SUSE/go-patch/patch"
+)
+
+var _ = Describe("SortOp.Apply", func() {
+	It("sorts scalars by type and value", func() {
+		res, err := SortOp{Path: MustNewPointerFromString("/a")}.Apply(
+			parseYAML(`{"a":["b",2,{"x":1},true,null,1.5,"a",false,[1]]}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(parseYAML(`{"a":[null,false,true,1.5,2,"a","b",{"x":1},[1]]}`)))
+	})
+
+	It("sorts maps by key value keeping items without key last", func() {
+		res, err := SortOp{Path: MustNewPointerFromString("/releases"), Key: "name"}.Apply(
+			parseYAML(`{"releases":[{"name":"c"},{"v":1},{"name":"a","v":1},{"name":"b"},{"name":"a","v":2}]}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(parseYAML(`{"releases":[{"name":"a","v":1},{"name":"a","v":2},{"name":"b"},{"name":"c"},{"v":1}]}`)))
+	})
+
+	It("sorts by nested keys and numbers regardless of their type", func() {
+		doc := []interface{}{
+			map[string]interface{}{"meta": map[string]interface{}{"id": float64(10)}},
+			map[string]interface{}{"meta": map[string]interface{}{"id": float64(9)}},
+		}
+
+		res, err := SortOp{Path: MustNewPointerFromString(""), Key: "meta.id"}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal([]interface{}{
+			map[string]interface{}{"meta": map[string]interface{}{"id": float64(9)}},
+			map[string]interface{}{"meta": map[string]interface{}{"id": float64(10)}},
+		}))
+	})
+
+	It("sorts every array matched by wildcard", func() {
+		res, err := SortOp{Path: MustNewPointerFromString("/*/tags")}.Apply(parseYAML(`[{"tags":["b","a"]},{"tags":[2,1]}]`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(parseYAML(`[{"tags":["a","b"]},{"tags":[1,2]}]`)))
+	})
+
+	It("does nothing if optional array is missing", func() {
+		res, err := SortOp{Path: MustNewPointerFromString("/a?/tags")}.Apply(parseYAML(`{}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(parseYAML(`{}`)))
+	})
+
+	It("returns an error if it's not an array", func() {
+		_, err := SortOp{Path: MustNewPointerFromString("/a")}.Apply(parseYAML(`{"a":1}`))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find an array at path '/a' but found 'int'"))
+	})
+})
@@ -1,13 +1,50 @@
 package patch_test
 
 import (
+	"encoding/json"
 	"testing"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v2"
+
+	. "github.com/SUSE/go-patch/patch"
 )
 
 func TestPatch(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "/")
 }
+
+// parseYAML decodes test fixtures the same way as yaml library decodes documents
+func parseYAML(str string) interface{} {
+	var val interface{}
+
+	err := yaml.Unmarshal([]byte(str), &val)
+	Expect(err).ToNot(HaveOccurred())
+
+	return val
+}
+
+// parseJSON decodes test fixtures the same way as encoding/json decodes documents
+func parseJSON(str string) interface{} {
+	var val interface{}
+
+	err := json.Unmarshal([]byte(str), &val)
+	Expect(err).ToNot(HaveOccurred())
+
+	return val
+}
+
+// parseOps builds operations from test fixtures the same way as they are loaded from operations files
+func parseOps(str string) Ops {
+	var opDefs []OpDefinition
+
+	err := yaml.Unmarshal([]byte(str), &opDefs)
+	Expect(err).ToNot(HaveOccurred())
+
+	ops, err := NewOpsFromDefinitions(opDefs)
+	Expect(err).ToNot(HaveOccurred())
+
+	return ops
+}
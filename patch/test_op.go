@@ -11,15 +11,26 @@ type TestOp struct {
 	Absent bool
 }
 
+func (op TestOp) path() Pointer           { return op.Path }
+func (op TestOp) withPath(ptr Pointer) Op { op.Path = ptr; return op }
+
 func (op TestOp) Apply(doc interface{}) (interface{}, error) {
+	return op.apply(doc, nil)
+}
+
+func (op TestOp) apply(doc interface{}, m matchers) (interface{}, error) {
+	if res, found, err := applyWildcard(op, doc, m); found {
+		return res, err
+	}
+
 	if op.Absent {
-		return op.checkAbsence(doc)
+		return op.checkAbsence(doc, m)
 	}
-	return op.checkValue(doc)
+	return op.checkValue(doc, m)
 }
 
-func (op TestOp) checkAbsence(doc interface{}) (interface{}, error) {
-	_, err := FindOp{Path: op.Path}.Apply(doc)
+func (op TestOp) checkAbsence(doc interface{}, m matchers) (interface{}, error) {
+	_, err := FindOp{Path: op.Path}.apply(doc, m)
 	if err != nil {
 		if typedErr, ok := err.(OpMissingIndexErr); ok {
 			if typedErr.Path.String() == op.Path.String() {
@@ -37,16 +48,22 @@ func (op TestOp) checkAbsence(doc interface{}) (interface{}, error) {
 	return nil, fmt.Errorf("Expected to not find '%s'", op.Path)
 }
 
-func (op TestOp) checkValue(doc interface{}) (interface{}, error) {
-	foundVal, err := FindOp{Path: op.Path}.Apply(doc)
+func (op TestOp) checkValue(doc interface{}, m matchers) (interface{}, error) {
+	foundVal, err := FindOp{Path: op.Path}.apply(doc, m)
 	if err != nil {
 		return nil, err
 	}
 
-	if !reflect.DeepEqual(foundVal, op.Value) {
+	if !op.matchesValue(foundVal) {
 		return nil, fmt.Errorf("Found value does not match expected value")
 	}
 
 	// Return same input document
 	return doc, nil
 }
+
+// matchesValue compares maps regardless of their flavor and numbers by their value
+// (e.g. map[string]interface{} and float64 from encoding/json)
+func (op TestOp) matchesValue(foundVal interface{}) bool {
+	return reflect.DeepEqual(jsonPatchNormalize(foundVal), jsonPatchNormalize(op.Value))
+}
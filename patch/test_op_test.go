@@ -101,4 +101,51 @@ var _ = Describe("TestOp.Apply", func() {
 			Expect(err.Error()).To(Equal("Expected to not find '/a'"))
 		})
 	})
+
+	Describe("wildcard", func() {
+		doc := []interface{}{
+			map[interface{}]interface{}{"name": "a", "disk": 1},
+			map[interface{}]interface{}{"name": "b", "disk": 1},
+		}
+
+		It("succeeds if every item matches", func() {
+			_, err := TestOp{Path: MustNewPointerFromString("/*/disk"), Value: 1}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = TestOp{Path: MustNewPointerFromString("/*/name"), Value: "a"}.Apply(doc)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Found value does not match expected value"))
+		})
+
+		It("succeeds if value is absent from every item", func() {
+			_, err := TestOp{Path: MustNewPointerFromString("/*/size"), Absent: true}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = TestOp{Path: MustNewPointerFromString("/*/disk"), Absent: true}.Apply(doc)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected to not find '/0/disk'"))
+		})
+
+		It("succeeds for empty collections", func() {
+			_, err := TestOp{Path: MustNewPointerFromString("/*/disk"), Value: 1}.Apply([]interface{}{})
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	Describe("all matching array items", func() {
+		It("succeeds if every matching item matches", func() {
+			doc := []interface{}{
+				map[interface{}]interface{}{"name": "a", "v": 1},
+				map[interface{}]interface{}{"name": "b", "v": 2},
+				map[interface{}]interface{}{"name": "a", "v": 1},
+			}
+
+			_, err := TestOp{Path: MustNewPointerFromString("/name=a:all/v"), Value: 1}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = TestOp{Path: MustNewPointerFromString("/name=b:all/v"), Value: 1}.Apply(doc)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Found value does not match expected value"))
+		})
+	})
 })
@@ -18,13 +18,44 @@ type MatchingIndexToken struct {
 	Value     string
 	Optional  bool
 	Modifiers []Modifier
+
+	// Operator determines how Value is compared (defaults to equality)
+	Operator MatchingOperator
+
+	// All refers to every matching item instead of requiring exactly one
+	All bool
+
+	// And lists conditions that matching item has to satisfy in addition to Key and Value
+	And []MatchingCondition
+}
+
+// MatchingCondition refers to a (possibly nested via dots, e.g. 'properties.port') key and its value
+type MatchingCondition struct {
+	Key      string
+	Value    string
+	Operator MatchingOperator
 }
 
+// MatchingOperator determines how condition value is compared to item values
+type MatchingOperator string
+
+const (
+	MatchingEqual  MatchingOperator = ""   // key=val
+	MatchingRegexp MatchingOperator = "~=" // key~=regexp
+	MatchingGlob   MatchingOperator = "*=" // key*=glob ('*' matches any characters)
+)
+
 type KeyToken struct {
 	Key      string
 	Optional bool
 }
 
+// WildcardToken refers to every array item or map value. Operations do not report
+// locations they were applied to; Pointer.Expand returns them for a given document.
+type WildcardToken struct {
+	Optional bool
+}
+
 type Modifier interface {
 	_modifier()
 }
@@ -0,0 +1,310 @@
+package patch
+
+import (
+	"fmt"
+)
+
+// walkMissing determines what happens when walker encounters optional location that does not exist
+type walkMissing int
+
+const (
+	// walkMissingStop stops walking and visits missing location (e.g. remove does nothing)
+	walkMissingStop walkMissing = iota
+	// walkMissingCreate creates missing parents within the document (e.g. replace)
+	walkMissingCreate
+	// walkMissingDetach creates missing parents without adding them to the document (e.g. find)
+	walkMissingDetach
+)
+
+// walker resolves path tokens against a document the same way for all operations.
+// Every token but the last one is followed to its value; location referred to
+// by the last token (or the first missing location if walking stops) is visited.
+// Operations only decide what to do with visited location.
+type walker struct {
+	Path    Pointer
+	Doc     walkDoc
+	Missing walkMissing
+
+	// Insertion makes last array token refer to a position to insert at
+	// (e.g. with ':before', ':after' or '-') instead of an existing item
+	Insertion bool
+}
+
+// walkDoc provides access to arrays and maps of a particular document representation
+// (e.g. yaml.v2 or encoding/json documents, or yaml.v3 nodes)
+type walkDoc interface {
+	Deref(obj interface{}) interface{}
+	Items(obj interface{}) ([]interface{}, bool)
+	MatchingIndices(obj interface{}, token MatchingIndexToken) ([]int, error)
+	Get(obj interface{}, key string) (val interface{}, found bool, isMap bool)
+
+	// ErrValue and MapKeys return values used in errors
+	ErrValue(obj interface{}) interface{}
+	MapKeys(obj interface{}) map[interface{}]interface{}
+
+	NewArray() interface{}
+	NewMap() interface{}
+	NewMatchingItem(MatchingIndexToken) interface{}
+
+	// Child returns value at existing location and a function to replace it
+	Child(walkLocation) (interface{}, func(interface{}))
+	// Attach adds new value at missing location and returns a function to replace it
+	Attach(walkLocation, interface{}) func(interface{})
+}
+
+// walkLocation is a concrete location within an array or a map
+type walkLocation struct {
+	Path   Pointer
+	Token  Token
+	Tokens []Token // consists of root, key and index tokens only
+
+	Parent interface{}       // array or map containing location
+	Update func(interface{}) // replaces parent within the document (arrays are reallocated when resized)
+
+	IsMap bool
+	Key   string
+	Index int // equals to number of items for after last index token and missing matching item
+
+	Insert bool // new item is to be inserted at Index
+	Found  bool // location exists
+}
+
+// Walk visits location referred to by the path; update replaces root of the document.
+// Path is expected to have tokens besides root token.
+func (w walker) Walk(obj interface{}, update func(interface{}), visit func(walkLocation) error) error {
+	tokens := w.Path.Tokens()
+	concreteTokens := []Token{RootToken{}}
+
+	for i, token := range tokens[1:] {
+		isLast := i == len(tokens)-2
+
+		loc, err := w.locate(w.Doc.Deref(obj), update, token, NewPointer(tokens[:i+2]), isLast)
+		if err != nil {
+			return err
+		}
+
+		if loc.IsMap {
+			concreteTokens = append(concreteTokens, KeyToken{Key: loc.Key})
+		} else {
+			concreteTokens = append(concreteTokens, IndexToken{Index: loc.Index})
+		}
+
+		if isLast || (!loc.Found && w.Missing == walkMissingStop) {
+			loc.Tokens = concreteTokens
+			return visit(loc)
+		}
+
+		if loc.Found {
+			obj, update = w.Doc.Child(loc)
+		} else {
+			obj, update, err = w.create(loc, tokens[i+2], NewPointer(tokens[:i+3]))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (w walker) locate(obj interface{}, update func(interface{}), token Token, currPath Pointer, isLast bool) (walkLocation, error) {
+	loc := walkLocation{Path: currPath, Token: token, Parent: obj, Update: update}
+
+	switch typedToken := token.(type) {
+	case IndexToken:
+		items, ok := w.Doc.Items(obj)
+		if !ok {
+			return loc, NewOpArrayMismatchTypeErr(currPath, w.Doc.ErrValue(obj))
+		}
+
+		return w.locateItem(loc, items, typedToken.Index, typedToken.Modifiers, isLast)
+
+	case AfterLastIndexToken:
+		items, ok := w.Doc.Items(obj)
+		if !ok {
+			return loc, NewOpArrayMismatchTypeErr(currPath, w.Doc.ErrValue(obj))
+		}
+
+		if !isLast {
+			return loc, fmt.Errorf("Expected after last index token to be last in path '%s'", w.Path)
+		}
+
+		if !w.Insertion {
+			return loc, OpUnexpectedTokenErr{token, currPath}
+		}
+
+		loc.Index = len(items)
+		loc.Insert = true
+
+		return loc, nil
+
+	case MatchingIndexToken:
+		items, ok := w.Doc.Items(obj)
+		if !ok {
+			return loc, NewOpArrayMismatchTypeErr(currPath, w.Doc.ErrValue(obj))
+		}
+
+		idxs, err := w.Doc.MatchingIndices(obj, typedToken)
+		if err != nil {
+			return loc, err
+		}
+
+		// modifiers cannot be applied relative to missing item
+		if typedToken.Optional && len(idxs) == 0 && len(typedToken.Modifiers) == 0 {
+			if w.Missing != walkMissingStop {
+				err := matchingCreationErr(typedToken, currPath)
+				if err != nil {
+					return loc, err
+				}
+			}
+
+			loc.Index = len(items)
+			loc.Insert = true
+
+			return loc, nil
+		}
+
+		if len(idxs) != 1 {
+			return loc, OpMultipleMatchingIndexErr{currPath, idxs}
+		}
+
+		return w.locateItem(loc, items, idxs[0], typedToken.Modifiers, isLast)
+
+	case KeyToken:
+		_, found, isMap := w.Doc.Get(obj, typedToken.Key)
+		if !isMap {
+			return loc, NewOpMapMismatchTypeErr(currPath, w.Doc.ErrValue(obj))
+		}
+
+		if !found && !typedToken.Optional {
+			return loc, OpMissingMapKeyErr{typedToken.Key, currPath, w.Doc.MapKeys(obj)}
+		}
+
+		loc.IsMap = true
+		loc.Key = typedToken.Key
+		loc.Found = found
+
+		return loc, nil
+
+	default:
+		return loc, OpUnexpectedTokenErr{token, currPath}
+	}
+}
+
+func (w walker) locateItem(loc walkLocation, items []interface{}, idx int, modifiers []Modifier, isLast bool) (walkLocation, error) {
+	if isLast && w.Insertion {
+		insIdx, err := ArrayInsertion{Index: idx, Modifiers: modifiers, Array: items, Path: loc.Path}.Concrete()
+		if err != nil {
+			return loc, err
+		}
+
+		loc.Index = insIdx.number
+		loc.Insert = insIdx.insert
+		loc.Found = !insIdx.insert
+
+		return loc, nil
+	}
+
+	concreteIdx, err := ArrayIndex{Index: idx, Modifiers: modifiers, Array: items, Path: loc.Path}.Concrete()
+	if err != nil {
+		return loc, err
+	}
+
+	loc.Index = concreteIdx
+	loc.Found = true
+
+	return loc, nil
+}
+
+// create makes a value for missing location based on the next token
+func (w walker) create(loc walkLocation, next Token, nextPath Pointer) (interface{}, func(interface{}), error) {
+	var obj interface{}
+
+	if loc.IsMap {
+		switch next.(type) {
+		case AfterLastIndexToken, MatchingIndexToken:
+			obj = w.Doc.NewArray()
+		case KeyToken:
+			obj = w.Doc.NewMap()
+		default:
+			errMsg := "Expected to find key, matching index or after last index token at path '%s'"
+			return nil, nil, fmt.Errorf(errMsg, nextPath)
+		}
+	} else {
+		obj = w.Doc.NewMatchingItem(loc.Token.(MatchingIndexToken))
+	}
+
+	if w.Missing == walkMissingDetach {
+		return obj, func(interface{}) {}, nil
+	}
+
+	return obj, w.Doc.Attach(loc, obj), nil
+}
+
+// ifaceWalkDoc provides access to documents consisting of []interface{} and maps (see docMap)
+type ifaceWalkDoc struct {
+	flavor   docMapFlavor
+	matchers matchers
+}
+
+func (ifaceWalkDoc) Deref(obj interface{}) interface{} { return obj }
+
+func (ifaceWalkDoc) Items(obj interface{}) ([]interface{}, bool) {
+	typedObj, ok := obj.([]interface{})
+	return typedObj, ok
+}
+
+func (d ifaceWalkDoc) MatchingIndices(obj interface{}, token MatchingIndexToken) ([]int, error) {
+	return matchingIndices(obj.([]interface{}), token, d.matchers)
+}
+
+func (ifaceWalkDoc) Get(obj interface{}, key string) (interface{}, bool, bool) {
+	typedObj, ok := newDocMap(obj)
+	if !ok {
+		return nil, false, false
+	}
+
+	val, found := typedObj.Get(key)
+
+	return val, found, true
+}
+
+func (ifaceWalkDoc) ErrValue(obj interface{}) interface{} { return obj }
+
+func (ifaceWalkDoc) MapKeys(obj interface{}) map[interface{}]interface{} {
+	typedObj, _ := newDocMap(obj)
+	return typedObj.IfaceMap()
+}
+
+func (ifaceWalkDoc) NewArray() interface{} { return []interface{}{} }
+
+func (d ifaceWalkDoc) NewMap() interface{} { return d.flavor.NewMap() }
+
+func (d ifaceWalkDoc) NewMatchingItem(token MatchingIndexToken) interface{} {
+	return d.flavor.NewMatchingItem(token)
+}
+
+func (ifaceWalkDoc) Child(loc walkLocation) (interface{}, func(interface{})) {
+	if loc.IsMap {
+		typedObj, _ := newDocMap(loc.Parent)
+		val, _ := typedObj.Get(loc.Key)
+		return val, func(newObj interface{}) { typedObj.Set(loc.Key, newObj) }
+	}
+
+	typedObj := loc.Parent.([]interface{})
+
+	return typedObj[loc.Index], func(newObj interface{}) { typedObj[loc.Index] = newObj }
+}
+
+func (ifaceWalkDoc) Attach(loc walkLocation, obj interface{}) func(interface{}) {
+	if loc.IsMap {
+		typedObj, _ := newDocMap(loc.Parent)
+		typedObj.Set(loc.Key, obj)
+		return func(newObj interface{}) { typedObj.Set(loc.Key, newObj) }
+	}
+
+	typedObj := append(loc.Parent.([]interface{}), obj)
+	loc.Update(typedObj)
+
+	return func(newObj interface{}) { typedObj[loc.Index] = newObj }
+}
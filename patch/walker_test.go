@@ -0,0 +1,72 @@
+package patch_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	yamlv2 "gopkg.in/yaml.v2"
+	"gopkg.in/yaml.v3"
+
+	. "github.com/SUSE/go-patch/patch"
+)
+
+var _ = Describe("Path traversal", func() {
+	docStr := `
+igs:
+- name: a
+  jobs: [j1, j2]
+- name: b
+- name: b
+`
+
+	// Every operation resolves all but the last token the same way
+	apply := func(ptr Pointer) []error {
+		var doc interface{}
+
+		err := yamlv2.Unmarshal([]byte(docStr), &doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		var node yaml.Node
+
+		err = yaml.Unmarshal([]byte(docStr), &node)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, findErr := FindOp{Path: ptr}.Apply(doc)
+		_, replaceErr := ReplaceOp{Path: ptr, Value: 1}.Apply(doc)
+		_, removeErr := RemoveOp{Path: ptr}.Apply(doc)
+		_, findNodeErr := FindOp{Path: ptr}.FindNode(&node)
+		replaceNodeErr := ReplaceOp{Path: ptr, Value: 1}.ApplyNode(&node)
+		removeNodeErr := RemoveOp{Path: ptr}.ApplyNode(&node)
+
+		return []error{findErr, replaceErr, removeErr, findNodeErr, replaceNodeErr, removeNodeErr}
+	}
+
+	It("returns the same errors from all operations", func() {
+		errs := map[string]string{
+			"/igs/name=b/key":    "Expected to find exactly one matching array item for path '/igs/name=b' but found 2",
+			"/igs/name=c/key":    "Expected to find exactly one matching array item for path '/igs/name=c' but found 0",
+			"/igs/2:next/key":    "Expected to find array index '3' but found array of length '3' for path '/igs/2:next'",
+			"/igs/key/key":       "Expected to find a map at path '/igs/key' but found '[]interface {}'",
+			"/igs/0/name/0/key":  "Expected to find an array at path '/igs/0/name/0' but found 'string'",
+			"/igs/0/other/key":   "Expected to find a map key 'other' for path '/igs/0/other' (found map keys: 'jobs', 'name')",
+			"/igs/0/jobs/-3/key": "Expected to find array index '-3' but found array of length '2' for path '/igs/0/jobs/-3'",
+		}
+
+		for path, errMsg := range errs {
+			for _, err := range apply(MustNewPointerFromString(path)) {
+				Expect(err).To(HaveOccurred(), path)
+				Expect(err.Error()).To(Equal(errMsg), path)
+			}
+		}
+	})
+
+	It("returns the same error from modifying operations if after last index token is not last", func() {
+		ptr := NewPointer([]Token{RootToken{}, KeyToken{Key: "igs"}, AfterLastIndexToken{}, KeyToken{Key: "key"}})
+
+		errs := apply(ptr)
+
+		for _, err := range []error{errs[1], errs[2], errs[4], errs[5]} {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected after last index token to be last in path '/igs/-/key'"))
+		}
+	})
+})
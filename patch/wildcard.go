@@ -0,0 +1,215 @@
+package patch
+
+import (
+	"fmt"
+)
+
+// expandWildcard replaces operation that has a wildcard token (or a matching index token
+// with All set) in its path with operations for every location referred to by that token
+// (only first such token is expanded; resulting operations expand the rest).
+// Modifying operations are ordered from last to first so that array insertions
+// and removals do not shift indices of locations that are yet to be modified.
+func expandWildcard(op Op, doc interface{}, m matchers) (Ops, bool, error) {
+	return expandWildcardFunc(op, wildcardFind(doc, m), m)
+}
+
+// applyWildcard applies expanded operations if path of the operation
+// refers to multiple locations (see expandWildcard)
+func applyWildcard(op pathOp, doc interface{}, m matchers) (interface{}, bool, error) {
+	ops, found, err := expandWildcard(op, doc, m)
+	if !found || err != nil {
+		return nil, found, err
+	}
+
+	res, err := ops.apply(doc, m)
+
+	return res, true, err
+}
+
+// pathOp is implemented by operations that modify or check location(s) referred to
+// by their path, so that they are expanded, copied on write and compiled the same way
+type pathOp interface {
+	Op
+	path() Pointer
+	withPath(Pointer) Op
+}
+
+// wildcardFindFunc returns value at given path; only its type, map keys
+// and array items are used to determine locations referred to by a wildcard
+type wildcardFindFunc func(Pointer) (interface{}, error)
+
+func wildcardFind(doc interface{}, m matchers) wildcardFindFunc {
+	return func(path Pointer) (interface{}, error) {
+		return FindOp{Path: path}.apply(doc, m)
+	}
+}
+
+// Expand returns pointers to every location referred to by wildcard tokens
+// and matching index tokens with All set (for example, to report array items
+// modified by an operation). Pointer without such tokens is returned as is.
+func (p Pointer) Expand(doc interface{}) ([]Pointer, error) {
+	return p.expand(doc, nil)
+}
+
+func (p Pointer) expand(doc interface{}, m matchers) ([]Pointer, error) {
+	ptrs, found, err := wildcardPointers(p, wildcardFind(doc, m), m)
+	if !found {
+		return []Pointer{p}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := []Pointer{}
+
+	for _, ptr := range ptrs {
+		expandedPtrs, err := ptr.expand(doc, m)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, expandedPtrs...)
+	}
+
+	return result, nil
+}
+
+func expandWildcardFunc(op Op, find wildcardFindFunc, m matchers) (Ops, bool, error) {
+	typedOp, ok := op.(pathOp)
+	if !ok {
+		return nil, false, nil
+	}
+
+	ptrs, found, err := wildcardPointers(typedOp.path(), find, m)
+	if !found || err != nil {
+		return nil, found, err
+	}
+
+	ops := Ops{}
+
+	for _, ptr := range ptrs {
+		if _, ok := op.(TestOp); ok {
+			ops = append(ops, typedOp.withPath(ptr))
+		} else {
+			ops = append(Ops{typedOp.withPath(ptr)}, ops...)
+		}
+	}
+
+	return ops, true, nil
+}
+
+// wildcardPointers returns pointers to every array item or map value (in key order)
+// found at the location of the first wildcard token, or to every matching array item
+// for matching index token with All set. Empty collections result in no pointers;
+// optional tokens also result in no pointers if collection is missing.
+func wildcardPointers(path Pointer, find wildcardFindFunc, m matchers) ([]Pointer, bool, error) {
+	tokens := path.Tokens()
+
+	i, found := wildcardIndex(path)
+	if !found {
+		return nil, false, nil
+	}
+
+	parentPath := NewPointer(tokens[:i])
+	currPath := NewPointer(tokens[:i+1])
+
+	var optional bool
+
+	switch typedToken := tokens[i].(type) {
+	case WildcardToken:
+		optional = typedToken.Optional
+	case MatchingIndexToken:
+		optional = typedToken.Optional
+	}
+
+	obj, err := find(parentPath)
+	if err != nil {
+		if optional && isMissingErr(err, parentPath) {
+			return nil, true, nil
+		}
+		return nil, true, err
+	}
+
+	if obj == nil && optional {
+		return nil, true, nil
+	}
+
+	var itemTokens []Token
+
+	switch typedToken := tokens[i].(type) {
+	case WildcardToken:
+		switch typedObj := obj.(type) {
+		case []interface{}:
+			for idx := range typedObj {
+				itemTokens = append(itemTokens, IndexToken{Index: idx})
+			}
+
+		default:
+			typedMap, ok := newDocMap(obj)
+			if !ok {
+				return nil, true, OpMismatchTypeErr{"a map or an array", currPath, obj}
+			}
+
+			for _, key := range typedMap.Keys() {
+				itemTokens = append(itemTokens, KeyToken{Key: key})
+			}
+		}
+
+	case MatchingIndexToken:
+		typedObj, ok := obj.([]interface{})
+		if !ok {
+			return nil, true, NewOpArrayMismatchTypeErr(currPath, obj)
+		}
+
+		idxs, err := matchingIndices(typedObj, typedToken, m)
+		if err != nil {
+			return nil, true, err
+		}
+
+		if len(idxs) == 0 && !optional {
+			return nil, true, fmt.Errorf("Expected to find at least one matching array item for path '%s'", currPath)
+		}
+
+		for _, idx := range idxs {
+			itemTokens = append(itemTokens, IndexToken{Index: idx, Modifiers: typedToken.Modifiers})
+		}
+	}
+
+	var ptrs []Pointer
+
+	for _, itemToken := range itemTokens {
+		newTokens := append([]Token{}, tokens[:i]...)
+		newTokens = append(newTokens, itemToken)
+		newTokens = append(newTokens, tokens[i+1:]...)
+		ptrs = append(ptrs, NewPointer(newTokens))
+	}
+
+	return ptrs, true, nil
+}
+
+// wildcardIndex returns position of the first token that refers to multiple locations
+func wildcardIndex(path Pointer) (int, bool) {
+	for i, token := range path.Tokens() {
+		switch typedToken := token.(type) {
+		case WildcardToken:
+			return i, true
+		case MatchingIndexToken:
+			if typedToken.All {
+				return i, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// isMissingErr returns true if error indicates that given location does not exist
+func isMissingErr(err error, path Pointer) bool {
+	switch typedErr := err.(type) {
+	case OpMissingIndexErr:
+		return typedErr.Path.String() == path.String()
+	case OpMissingMapKeyErr:
+		return typedErr.Path.String() == path.String()
+	default:
+		return false
+	}
+}